@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"math"
+	"slices"
+
 	"github.com/rafibayer/ants-again/util"
 	"github.com/rafibayer/ants-again/vector"
 )
@@ -12,14 +16,49 @@ const (
 	RETURN
 )
 
+var antStates = []string{FORAGE: "forage", RETURN: "return"}
+
+func (s AntState) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(antStates) {
+		return nil, fmt.Errorf("unknown ant state %d", s)
+	}
+
+	return []byte(antStates[s]), nil
+}
+
+func (s *AntState) UnmarshalText(text []byte) error {
+	i := slices.Index(antStates, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown ant state %q, must be one of %v", text, antStates)
+	}
+
+	*s = AntState(i)
+	return nil
+}
+
 type Ant struct {
 	// position
 	vector.Vector
 
 	dir   vector.Vector
 	state AntState
+	caste int // index into the games castes
 
 	pheromoneStored int
+	repellentStored int     // repellent left to drop after finding exhausted food
+	alarmStored     int     // alarm pheromone left to drop after being scared
+	carrying        float32 // quality of the food being carried, while returning
+
+	tripTicks int // ticks since the last state change
+	lastTrip  int // duration of the previous trip in ticks, 0 before the first
+
+	home vector.Vector // estimated displacement from the hill, see PathIntegration
+
+	walked float64 // distance walked since refueling at the hill, the ant starves once it reaches AntEnergy
+	age    int     // ticks since spawning
+	dead   bool    // removed at the end of the tick
+
+	id int // unique within the game, in spawn order
 }
 
 type BoundaryMode int
@@ -35,105 +74,205 @@ func (g *Game) updateAnts() {
 	g.foragingAntCount = 0
 	g.returningAntCount = 0
 
-	for _, ant := range g.ants {
-		destination := ant.Add(ant.dir.Normalize().Mul(g.params.AntSpeed))
-
-		push := vector.ZERO
-		for obs := range g.obstacles.RadialSearchIter(destination, OBSTACLE_HASH_CELL_SIZE) {
-			delta := ant.Vector.Sub(obs.Vector)
-			if delta.Magnitude() > 0 {
-				push = push.Add(delta.Normalize())
-			}
+	behavior := g.behavior()
+	for i, ant := range g.ants {
+		// caught by a predator this tick
+		if ant.dead {
+			continue
 		}
 
-		if push == vector.ZERO {
-			ant.Vector = ant.Add(ant.dir.Normalize().Mul(g.params.AntSpeed))
-		} else {
-			avoid := push.Normalize()
-			ant.dir = ant.dir.Add(avoid.Mul(g.params.AntSpeed)).Normalize()
-		}
+		caste := g.casteOf(ant)
+		speed := g.antSpeed(ant)
 
-		g.keepInbounds(ant)
+		steering := Steering{Index: i, Caste: caste, Speed: speed, SenseRadius: g.senseRadius(ant), Phase: BeforeMove}
+		behavior.Steer(g, ant, &steering)
 
-		if util.Chance(g.params.PheromoneSenseProb) {
-			// pheromone field to search based on ant state
-			pheromone := g.returningPheromone
-			if ant.state == RETURN {
-				pheromone = g.foragingPheromone
-			}
-
-			// influence direction based on pheromone
-			pheromoneDir := vector.ZERO
-
-			nearby := pheromone.RadialSearchIter(ant.Vector, g.params.PheromoneSenseRadius)
-
-			for pher := range nearby {
-				// direction to pheromone and signal strength
-				dirToSpot := pher.Sub(ant.Vector).Normalize()
-
-				// scale by weight, distance to ant, and angular similarity
-				strength := float64(pher.amount)
-				strength = strength / max(0.1, ant.Vector.Distance(*pher.Vector)) // prevent overweighting really close smells
-
-				cosineSim := ant.dir.CosineSimilarity(dirToSpot)
-				if cosineSim < g.params.PheromoneSenseCosineSimilarity {
-					strength *= 0
-				}
-				strength *= cosineSim
-
-				pheromoneDir = pheromoneDir.Add(dirToSpot.Mul(strength))
-			}
+		walked := 0.0
+		if !steering.Hold {
+			before := ant.Vector
+			g.moveAnt(ant, ant.dir.Normalize().Mul(speed))
+			moved := ant.Sub(before)
+			g.integratePath(ant, moved)
+			walked = moved.Magnitude() // walls can block or shorten the step
+		}
 
-			ant.dir = ant.dir.Add(pheromoneDir.Mul(g.params.PheromoneInfluence))
-			ant.dir = ant.dir.Normalize()
+		g.keepInbounds(ant)
+		g.checkHazards(ant)
+		if ant.dead {
+			continue
 		}
 
+		steering.Phase = AfterMove
+		behavior.Steer(g, ant, &steering)
+
 		if ant.state == FORAGE {
 			g.foragingAntCount++
 			// check for food nearby, change state and turn around
 			nearFood := g.food.RadialSearchIter(ant.Vector, ANT_FOOD_RADIUS)
 
+			exhausted := false
 			for food := range nearFood {
-				if food.amount > 0 {
+				if food.amount <= 0 {
+					exhausted = true
+				} else {
+					exhausted = false
 					ant.state = RETURN
+					ant.carrying = food.quality
+					ant.endTrip()
 					food.amount--
+					g.pickedFoodCount++
+					if food.patch != nil {
+						food.patch.collected++
+					}
 					ant.dir = ant.dir.Mul(-1.0)
-					ant.pheromoneStored = g.params.AntPheromoneStart
+
+					// better food is worth recruiting more ants to
+					ant.pheromoneStored = int(math.Round(float64(g.params.AntPheromoneStart) * float64(food.quality)))
 					break // only grab 1 food
 				}
 			}
+
+			// only empty sources here, warn the others and look elsewhere
+			if exhausted && ant.repellentStored == 0 && g.params.AntRepellentStart > 0 {
+				ant.repellentStored = g.params.AntRepellentStart
+				ant.dir = ant.dir.Mul(-1.0)
+			}
 		}
 
 		if ant.state == RETURN {
 			g.returningAntCount++
-			nearHill := g.hills.RadialSearchIter(ant.Vector, ANT_HILL_RADIUS)
 
 			// check for hill nearby, change state and turn around
-			for range nearHill {
+			if g.atHill(ant.Vector) {
 				// turn around and go back to foraging
 				ant.state = FORAGE
+				ant.carrying = 0
+				ant.endTrip()
 				g.collectedFood++
+				g.storedFood++
+				g.casteCollected[ant.caste]++
 				ant.dir = ant.dir.Mul(-1.0)
 				ant.pheromoneStored = g.params.AntPheromoneStart
-				break
+				ant.home = vector.ZERO
 			}
 		}
 
-		if ant.pheromoneStored > 0 && util.Chance(g.params.PheromoneDropProb) {
+		drop := ant.pheromoneStored > 0 && util.Chance(g.params.PheromoneDropProb*caste.PheromoneDropProbScale)
+		if g.controlled {
+			drop = ant.pheromoneStored > 0 && g.action(i).Drop
+		}
+
+		if drop {
+			amount := g.depositStrength(ant)
 			ant.pheromoneStored--
-			switch ant.state {
-			case FORAGE:
-				g.foragingPheromone.Insert(&Pheromone{Vector: &vector.Vector{X: ant.X, Y: ant.Y}, amount: 1.0})
-			case RETURN:
-				g.returningPheromone.Insert(&Pheromone{Vector: &vector.Vector{X: ant.X, Y: ant.Y}, amount: 1.0})
+			for kind, pheromoneType := range g.pheromoneTypes {
+				if slices.Contains(pheromoneType.DroppedBy, ant.state) {
+					g.dropPheromone(PheromoneType(kind), ant, amount)
+				}
 			}
 		}
 
-		// randomly rotate a few degrees
-		ant.dir = ant.dir.Rotate(util.Rand(-g.params.AntRotation, g.params.AntRotation))
+		if ant.repellentStored > 0 && util.Chance(g.params.PheromoneDropProb*caste.PheromoneDropProbScale) {
+			ant.repellentStored--
+			g.dropPheromone(PheromoneRepellent, ant, 1.0)
+		}
+
+		if ant.alarmStored > 0 && util.Chance(g.params.PheromoneDropProb*caste.PheromoneDropProbScale) {
+			ant.alarmStored--
+			g.dropPheromone(PheromoneAlarm, ant, 1.0)
+		}
+
+		ant.tripTicks++
+
+		steering.Phase = EndOfTick
+		behavior.Steer(g, ant, &steering)
+
+		g.updateLifecycle(ant, walked)
 	}
 }
 
+// senseRadius is the ants pheromone sense radius, scaled by its caste.
+func (g *Game) senseRadius(ant *Ant) float64 {
+	return g.params.PheromoneSenseRadius * g.casteOf(ant).SenseRadiusScale
+}
+
+// sensePheromones sums the pull of the pheromone fields the ant responds to in its current state.
+// repellent pheromones push the ant away.
+func (g *Game) sensePheromones(ant *Ant, senseRadius float64) vector.Vector {
+	pheromoneDir := vector.ZERO
+
+	occlusion := OcclusionMode(g.params.PheromoneOcclusionModeIndex)
+	var occ occluders
+	if occlusion != OcclusionOff {
+		occ = g.occludersNear(ant.Vector, senseRadius)
+	}
+
+	for kind, field := range g.pheromones {
+		pheromoneType := &g.pheromoneTypes[kind]
+		if !slices.Contains(pheromoneType.SensedBy, ant.state) {
+			continue
+		}
+
+		for pher := range field.RadialSearchIter(ant.Vector, senseRadius) {
+			// marks right under the ant, like the one it just dropped, have no direction
+			if *pher.Vector == ant.Vector {
+				continue
+			}
+
+			// direction to pheromone and signal strength
+			dirToSpot := pher.Sub(ant.Vector).Normalize()
+
+			// scale by weight, distance to ant, and angular similarity
+			strength := float64(pher.amount)
+			strength = strength / max(0.1, ant.Vector.Distance(*pher.Vector)) // prevent overweighting really close smells
+
+			cosineSim := ant.dir.CosineSimilarity(dirToSpot)
+			if cosineSim < g.params.PheromoneSenseCosineSimilarity {
+				strength *= 0
+			}
+			strength *= cosineSim
+
+			// line of sight, only checked for pheromones we'd otherwise sense
+			if occlusion != OcclusionOff && strength > 0 && occ.blocks(ant.Vector, *pher.Vector) {
+				if occlusion == OcclusionIgnore {
+					continue
+				}
+				strength *= g.params.PheromoneOcclusionAttenuation
+			}
+
+			pheromoneDir = pheromoneDir.Add(dirToSpot.Mul(strength * pheromoneType.Sign))
+		}
+	}
+
+	return pheromoneDir
+}
+
+func (ant *Ant) endTrip() {
+	ant.lastTrip = ant.tripTicks
+	ant.tripTicks = 0
+}
+
+// integratePath accumulates the ants displacement from the hill, with error.
+// like desert ants, each step is measured slightly wrong so the estimate drifts over long trips.
+func (g *Game) integratePath(ant *Ant, step vector.Vector) {
+	if !g.params.PathIntegration {
+		return
+	}
+
+	noise := g.params.PathIntegrationNoise
+	ant.home = ant.home.Add(step.Rotate(util.Rand(-noise, noise)))
+}
+
+// steerHome blends the ants direction towards the hill according to its home vector.
+func (g *Game) steerHome(ant *Ant) {
+	if ant.home.Magnitude() == 0 {
+		return
+	}
+
+	toHome := ant.home.Mul(-1).Normalize()
+	ant.dir = ant.dir.Normalize().Add(toHome.Mul(g.params.PathIntegrationWeight)).Normalize()
+}
+
 func (g *Game) keepInbounds(ant *Ant) {
 	mode := BoundaryMode(g.params.BoundaryModeIndex)
 
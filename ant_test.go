@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathIntegration(t *testing.T) {
+	hill := vector.Vector{X: 500, Y: 500}
+
+	game := newTestGame(Scenario{Hills: []vector.Vector{hill}}, func(p *Params) {
+		p.AntCount = 50
+		p.PathIntegration = true
+		p.PathIntegrationNoise = 0
+	})
+
+	for range 100 {
+		game.updateAnts()
+	}
+
+	for _, ant := range game.ants {
+		require.InDelta(t, ant.X-hill.X, ant.home.X, 1e-6)
+		require.InDelta(t, ant.Y-hill.Y, ant.home.Y, 1e-6)
+	}
+
+	// returning ants are turned towards home
+	for _, ant := range game.ants {
+		ant.dir = ant.home.Perpendicular()
+		game.steerHome(ant)
+		require.Greater(t, ant.dir.CosineSimilarity(ant.home.Mul(-1)), 0.0)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// DEFAULT_BEHAVIOR is used when Params.Behavior is empty.
+const DEFAULT_BEHAVIOR = "default"
+
+// Behavior steers an ant, it's called once per Phase of each ants tick, see Steering.Phase.
+// behaviors only change the ants direction, picking up food, returning it and depositing pheromones
+// are handled by updateAnts for every behavior.
+type Behavior interface {
+	Steer(g *Game, ant *Ant, s *Steering)
+}
+
+// Phase is the point in an ants tick a behavior is steering at.
+type Phase int
+
+const (
+	BeforeMove Phase = iota // the ant is about to move along its heading, set Steering.Hold to stay put
+	AfterMove               // moved, before picking up or delivering food
+	EndOfTick               // after depositing pheromones, the heading is the one checked before the next move
+)
+
+// Steering is the per tick context passed to behaviors.
+type Steering struct {
+	Index       int // of the ant in the games ants
+	Caste       *Caste
+	Speed       float64
+	SenseRadius float64
+	Phase       Phase
+
+	Hold bool // set before moving to skip moving this tick
+}
+
+// BehaviorFunc adapts a function to a Behavior.
+type BehaviorFunc func(g *Game, ant *Ant, s *Steering)
+
+func (f BehaviorFunc) Steer(g *Game, ant *Ant, s *Steering) {
+	f(g, ant, s)
+}
+
+// Composite runs its behaviors in order, each phase.
+type Composite []Behavior
+
+func (c Composite) Steer(g *Game, ant *Ant, s *Steering) {
+	for _, b := range c {
+		b.Steer(g, ant, s)
+	}
+}
+
+// Avoid turns the ant away from obstacles in its path, holding it in place for the tick.
+type Avoid struct{}
+
+func (Avoid) Steer(g *Game, ant *Ant, s *Steering) {
+	if s.Phase != BeforeMove {
+		return
+	}
+
+	destination := ant.Add(ant.dir.Normalize().Mul(s.Speed))
+
+	push := vector.ZERO
+	for obs := range g.obstacles.RadialSearchIter(destination, OBSTACLE_CELL_SIZE) {
+		delta := ant.Vector.Sub(obs.Vector)
+		if delta.Magnitude() > 0 {
+			push = push.Add(delta.Normalize())
+		}
+	}
+
+	if push != vector.ZERO {
+		avoid := push.Normalize()
+		ant.dir = ant.dir.Add(avoid.Mul(s.Speed)).Normalize()
+		s.Hold = true
+	}
+}
+
+// FollowTrail occasionally steers the ant along the pheromones it senses, see sensePheromones.
+type FollowTrail struct{}
+
+func (FollowTrail) Steer(g *Game, ant *Ant, s *Steering) {
+	if s.Phase != AfterMove {
+		return
+	}
+
+	if !util.Chance(g.params.PheromoneSenseProb * s.Caste.PheromoneSenseProbScale) {
+		return
+	}
+
+	pheromoneDir := g.sensePheromones(ant, s.SenseRadius)
+	ant.dir = ant.dir.Add(pheromoneDir.Mul(g.params.PheromoneInfluence * s.Caste.PheromoneInfluenceScale))
+	ant.dir = ant.dir.Normalize()
+}
+
+// Home steers returning ants towards the hill by path integration, if enabled.
+type Home struct{}
+
+func (Home) Steer(g *Game, ant *Ant, s *Steering) {
+	if s.Phase == AfterMove && g.params.PathIntegration && ant.state == RETURN {
+		g.steerHome(ant)
+	}
+}
+
+// Wander randomly rotates the ant a few degrees.
+type Wander struct{}
+
+func (Wander) Steer(g *Game, ant *Ant, s *Steering) {
+	if s.Phase != EndOfTick {
+		return
+	}
+
+	rotation := g.params.AntRotation * s.Caste.RotationScale
+	ant.dir = ant.dir.Rotate(util.Rand(-rotation, rotation))
+}
+
+// controlledBehavior follows the actions of an external policy, see env.go.
+var controlledBehavior = Composite{Avoid{}, Home{}, BehaviorFunc(func(g *Game, ant *Ant, s *Steering) {
+	if s.Phase == EndOfTick {
+		ant.dir = ant.dir.Rotate(g.action(s.Index).Turn)
+	}
+})}
+
+var behaviors = map[string]Behavior{
+	DEFAULT_BEHAVIOR: Composite{Avoid{}, FollowTrail{}, Home{}, Wander{}},
+	"no wander":      Composite{Avoid{}, FollowTrail{}, Home{}},
+	"random walk":    Composite{Avoid{}, Wander{}},
+}
+
+// RegisterBehavior makes a behavior selectable by name with Params.Behavior.
+// it panics if the name is already registered, like database/sql.Register.
+func RegisterBehavior(name string, b Behavior) {
+	if _, ok := behaviors[name]; ok {
+		panic(fmt.Sprintf("behavior %q registered twice", name))
+	}
+
+	behaviors[name] = b
+}
+
+// behaviorNames returns the registered behaviors, sorted.
+func behaviorNames() []string {
+	return slices.Sorted(maps.Keys(behaviors))
+}
+
+func validBehavior(name string) error {
+	if _, ok := behaviors[name]; name != "" && !ok {
+		return fmt.Errorf("unknown behavior %q, registered: %v", name, behaviorNames())
+	}
+
+	return nil
+}
+
+// behavior returns the behavior steering the games ants.
+func (g *Game) behavior() Behavior {
+	if g.controlled {
+		return controlledBehavior
+	}
+
+	if b, ok := behaviors[g.params.Behavior]; ok {
+		return b
+	}
+
+	return behaviors[DEFAULT_BEHAVIOR]
+}
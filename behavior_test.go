@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterBehavior(t *testing.T) {
+	steered := 0
+	RegisterBehavior("test straight", BehaviorFunc(func(g *Game, ant *Ant, s *Steering) {
+		if s.Phase != BeforeMove {
+			return
+		}
+		steered++
+		ant.dir = vector.Vector{X: 1}
+	}))
+	t.Cleanup(func() { delete(behaviors, "test straight") })
+
+	require.Panics(t, func() { RegisterBehavior("test straight", Wander{}) })
+	require.Contains(t, behaviorNames(), "test straight")
+
+	params := DefaultParams.clone()
+	require.NoError(t, overlayParams(params, json.RawMessage(`{"AntCount": 2, "Behavior": "test straight"}`)))
+
+	game := NewGame(params, &Scenario{Hills: []vector.Vector{{X: 500, Y: 500}}})
+	start := game.ants[0].Vector
+	for range 10 {
+		require.NoError(t, game.Update())
+	}
+
+	require.Equal(t, 20, steered)
+	require.InDelta(t, start.Y, game.ants[0].Y, 1e-9)
+	require.Greater(t, game.ants[0].X, start.X)
+}
+
+func TestUnknownBehavior(t *testing.T) {
+	err := overlayParams(DefaultParams.clone(), json.RawMessage(`{"Behavior": "nope"}`))
+	require.ErrorContains(t, err, "unknown behavior")
+}
+
+func TestAvoidHolds(t *testing.T) {
+	game := NewGame(nil, &Scenario{Hills: []vector.Vector{{X: 100, Y: 100}}})
+	ant := &Ant{Vector: vector.Vector{X: 500, Y: 500}, dir: vector.Vector{X: 1}}
+	game.obstacles.Insert(&Obstacle{Vector: vector.Vector{X: 505, Y: 500}})
+
+	s := Steering{Caste: &game.params.Castes[0], Speed: 2}
+	Composite{Avoid{}, Wander{}}.Steer(game, ant, &s)
+	require.True(t, s.Hold)
+	require.Less(t, ant.dir.X, 1.0)
+
+	s = Steering{Caste: &game.params.Castes[0], Speed: 2}
+	ant.Vector = vector.Vector{X: 200, Y: 200}
+	Avoid{}.Steer(game, ant, &s)
+	require.False(t, s.Hold)
+}
+
+// baselineSteering is the steering updateAnts did inline before behaviors were factored out.
+var baselineSteering = BehaviorFunc(func(g *Game, ant *Ant, s *Steering) {
+	switch s.Phase {
+	case BeforeMove:
+		destination := ant.Add(ant.dir.Normalize().Mul(s.Speed))
+
+		push := vector.ZERO
+		for obs := range g.obstacles.RadialSearchIter(destination, OBSTACLE_CELL_SIZE) {
+			delta := ant.Vector.Sub(obs.Vector)
+			if delta.Magnitude() > 0 {
+				push = push.Add(delta.Normalize())
+			}
+		}
+
+		if push != vector.ZERO {
+			avoid := push.Normalize()
+			ant.dir = ant.dir.Add(avoid.Mul(s.Speed)).Normalize()
+			s.Hold = true
+		}
+	case AfterMove:
+		if util.Chance(g.params.PheromoneSenseProb * s.Caste.PheromoneSenseProbScale) {
+			pheromoneDir := g.sensePheromones(ant, s.SenseRadius)
+
+			ant.dir = ant.dir.Add(pheromoneDir.Mul(g.params.PheromoneInfluence * s.Caste.PheromoneInfluenceScale))
+			ant.dir = ant.dir.Normalize()
+		}
+
+		if g.params.PathIntegration && ant.state == RETURN {
+			g.steerHome(ant)
+		}
+	case EndOfTick:
+		rotation := g.params.AntRotation * s.Caste.RotationScale
+		ant.dir = ant.dir.Rotate(util.Rand(-rotation, rotation))
+	}
+})
+
+func TestDefaultBehaviorMatchesBaseline(t *testing.T) {
+	RegisterBehavior("test baseline", baselineSteering)
+	t.Cleanup(func() { delete(behaviors, "test baseline") })
+
+	scenario := &Scenario{
+		Hills: []vector.Vector{{X: 500, Y: 500}},
+		Food:  []FoodPatch{{X: 530, Y: 480, Rows: 5, Cols: 5}},
+	}
+	// a wall of obstacles between the hill and the food
+	for y := 440.0; y <= 560; y += OBSTACLE_CELL_SIZE {
+		scenario.Obstacles = append(scenario.Obstacles, vector.Vector{X: 515, Y: y})
+	}
+
+	run := func(behavior string) []Ant {
+		params := DefaultParams.clone()
+		params.AntCount = 50
+		params.PathIntegration = true
+		params.Behavior = behavior
+
+		util.Seed(1)
+		game := NewGame(params, scenario)
+		for range 300 {
+			require.NoError(t, game.Update())
+		}
+
+		ants := []Ant{}
+		for _, ant := range game.ants {
+			ants = append(ants, Ant{Vector: ant.Vector, dir: ant.dir, state: ant.state})
+		}
+		return ants
+	}
+
+	want := run("test baseline")
+	require.Equal(t, want, run(DEFAULT_BEHAVIOR))
+	require.NotEqual(t, want, run("no wander"))
+}
+
+func TestAvoidCheckedHeadingIsMoved(t *testing.T) {
+	var from, checked vector.Vector
+	var phases []Phase
+	record := BehaviorFunc(func(g *Game, ant *Ant, s *Steering) {
+		phases = append(phases, s.Phase)
+		if s.Phase == BeforeMove {
+			from, checked = ant.Vector, ant.dir.Normalize()
+		}
+	})
+	RegisterBehavior("test checked", Composite{Avoid{}, record, FollowTrail{}, Home{}, Wander{}})
+	t.Cleanup(func() { delete(behaviors, "test checked") })
+
+	params := DefaultParams.clone()
+	params.AntCount = 1
+	params.Behavior = "test checked"
+	game := NewGame(params, &Scenario{Hills: []vector.Vector{{X: 500, Y: 500}}})
+
+	for range 20 {
+		phases = nil
+		require.NoError(t, game.Update())
+
+		// later phases turn the ant, but only after it moved along the heading avoid checked
+		require.Equal(t, []Phase{BeforeMove, AfterMove, EndOfTick}, phases)
+		moved := game.ants[0].Sub(from).Normalize()
+		require.InDelta(t, checked.X, moved.X, 1e-9)
+		require.InDelta(t, checked.Y, moved.Y, 1e-9)
+	}
+}
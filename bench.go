@@ -0,0 +1,43 @@
+// headless benchmark of the simulation tick, to catch perf regressions
+// before they land in the WASM build. see also game_test.go for go benchmarks.
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	BENCH_WARMUP_TICKS = TPS
+	BENCH_TICKS        = 10 * TPS
+)
+
+// runBench runs the simulation without rendering for each ant count,
+// printing the achieved ticks/sec.
+func runBench(scenario *Scenario, antCounts []int, ticks int) error {
+	for _, ants := range antCounts {
+		params := scenario.withParams(&DefaultParams)
+		params.AntCount = ants
+		game := NewGame(params, scenario)
+
+		// let the colony spread out and lay some pheromones before measuring
+		for range BENCH_WARMUP_TICKS {
+			if err := game.Update(); err != nil {
+				return err
+			}
+		}
+
+		start := time.Now()
+		for range ticks {
+			if err := game.Update(); err != nil {
+				return err
+			}
+		}
+		elapsed := time.Since(start)
+
+		fmt.Printf("ants=%d ticks=%d elapsed=%s ticks/sec=%.1f\n",
+			ants, ticks, elapsed.Round(time.Millisecond), float64(ticks)/elapsed.Seconds())
+	}
+
+	return nil
+}
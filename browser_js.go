@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"syscall/js"
+)
+
+// browserScenario loads the scenario named by the pages ?scenario= query parameter, e.g. game.html?scenario=scenarios/maze.json.
+// the browser build has no flags or file system, so the scenario and the files it references are fetched relative to the page.
+// it returns nil if there's no parameter, for the default scenario.
+func browserScenario() (*Scenario, error) {
+	page, err := url.Parse(js.Global().Get("location").Get("href").String())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing page url: %w", err)
+	}
+
+	path := page.Query().Get("scenario")
+	if path == "" {
+		return nil, nil
+	}
+
+	return loadScenarioWith(func(name string) ([]byte, error) {
+		return fetch(page, name)
+	}, path)
+}
+
+// fetch gets name relative to the page.
+func fetch(page *url.URL, name string) ([]byte, error) {
+	ref, err := url.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(page.ResolveReference(ref).String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching %s: %s", name, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,9 @@
+//go:build !js
+
+package main
+
+// browserScenario is the scenario from the pages url in the browser build, see browser_js.go.
+// other builds use the --scenario flag.
+func browserScenario() (*Scenario, error) {
+	return nil, nil
+}
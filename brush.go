@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math"
+	"slices"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+const (
+	BRUSH_MAX_POINTS = 10_000 // food or obstacles placed by one shape, the rest are dropped
+	BRUSH_ANT_COUNT  = 50     // default ants spawned per click
+)
+
+type BrushShape int
+
+const (
+	BrushFreehand  BrushShape = iota // paint while dragging
+	BrushLine                        // drag from one end to the other
+	BrushRectangle                   // drag between opposite corners, filled
+	BrushCircle                      // drag from the center to the edge, filled
+)
+
+var brushShapes = []string{"freehand", "line", "rectangle", "circle"}
+
+// brushPoints returns the points of a grid with the given spacing covered by the shape from a to b.
+// freehand shapes are a disc of radius around b, and lines are thickened by radius.
+// a freehand brush with no radius is just b.
+func brushPoints(shape BrushShape, a, b vector.Vector, radius, spacing float64) []vector.Vector {
+	if shape == BrushFreehand && radius == 0 {
+		return []vector.Vector{b}
+	}
+
+	// thin shapes still get a row of points
+	thick := max(radius, spacing)
+
+	var lo, hi vector.Vector
+	var inside func(p vector.Vector) bool
+	switch shape {
+	case BrushLine:
+		lo = vector.Vector{X: min(a.X, b.X) - thick, Y: min(a.Y, b.Y) - thick}
+		hi = vector.Vector{X: max(a.X, b.X) + thick, Y: max(a.Y, b.Y) + thick}
+		inside = func(p vector.Vector) bool { return vector.DistanceToSegment(p, a, b) <= thick }
+	case BrushRectangle:
+		lo = vector.Vector{X: min(a.X, b.X), Y: min(a.Y, b.Y)}
+		hi = vector.Vector{X: max(a.X, b.X), Y: max(a.Y, b.Y)}
+		inside = func(p vector.Vector) bool { return true }
+	case BrushCircle:
+		r := max(a.Distance(b), spacing)
+		lo = vector.Vector{X: a.X - r, Y: a.Y - r}
+		hi = vector.Vector{X: a.X + r, Y: a.Y + r}
+		inside = func(p vector.Vector) bool { return p.Distance(a) <= r }
+	default:
+		lo = vector.Vector{X: b.X - thick, Y: b.Y - thick}
+		hi = vector.Vector{X: b.X + thick, Y: b.Y + thick}
+		inside = func(p vector.Vector) bool { return p.Distance(b) <= thick }
+	}
+
+	// clamp to the world
+	lo = vector.Vector{X: max(lo.X, 0), Y: max(lo.Y, 0)}
+	hi = vector.Vector{X: min(hi.X, GAME_SIZE-1), Y: min(hi.Y, GAME_SIZE-1)}
+
+	points := []vector.Vector{}
+	for x := math.Ceil(lo.X/spacing) * spacing; x <= hi.X; x += spacing {
+		for y := math.Ceil(lo.Y/spacing) * spacing; y <= hi.Y; y += spacing {
+			p := vector.Vector{X: x, Y: y}
+			if !inside(p) {
+				continue
+			}
+
+			points = append(points, p)
+			if len(points) == BRUSH_MAX_POINTS {
+				return points
+			}
+		}
+	}
+
+	return points
+}
+
+// paint fills the shape from a to b with food or obstacles, skipping spots already filled.
+func (g *Game) paint(mode CursorMode, shape BrushShape, a, b vector.Vector) {
+	switch mode {
+	case CursorModeFood:
+		for _, p := range brushPoints(shape, a, b, g.brushRadius, FOOD_SPACING) {
+			if len(g.food.RadialSearch(p, FOOD_SPACING/2)) > 0 {
+				continue
+			}
+			g.editAddFood(&Food{amount: g.brushFoodAmount, capacity: g.brushFoodAmount, quality: 1, Vector: &p})
+		}
+	case CursorModeObstacle:
+		for _, p := range brushPoints(shape, a, b, g.brushRadius, OBSTACLE_CELL_SIZE) {
+			if len(g.obstacles.RadialSearch(p, OBSTACLE_CELL_SIZE/2)) > 0 {
+				continue
+			}
+			g.editAddObstacle(&Obstacle{Vector: p})
+		}
+	default:
+	}
+}
+
+// spawnAnts spawns count ants spread within the brush radius of v.
+// they're placed, not born, so like the starting ants they aren't counted in bornAntCount.
+func (g *Game) spawnAnts(v vector.Vector, count int) {
+	ants := make([]*Ant, 0, count)
+	for range count {
+		offset := vector.Vector{X: g.brushRadius}.Rotate(util.Rand(0, 360)).Mul(math.Sqrt(util.Rand(0, 1)))
+		ants = append(ants, g.spawnAnt(v.Add(offset)))
+	}
+
+	// spawnAnt already added them
+	g.history.record(edit{
+		undo: func() { g.removeAnts(ants) },
+		redo: func() { g.restoreAnts(ants) },
+	})
+}
+
+func (g *Game) editRemoveAnts(ants []*Ant) {
+	if len(ants) == 0 {
+		return
+	}
+
+	// ants may share g.ants backing array, which removeAnts overwrites
+	ants = slices.Clone(ants)
+	g.edit(func() { g.removeAnts(ants) }, func() { g.restoreAnts(ants) })
+}
+
+func (g *Game) removeAnts(ants []*Ant) {
+	g.ants = slices.DeleteFunc(g.ants, func(ant *Ant) bool {
+		return slices.Contains(ants, ant)
+	})
+}
+
+// restoreAnts puts back removed ants. ants that died in the meantime were already
+// counted by updateColony, they stay gone so they aren't counted twice.
+func (g *Game) restoreAnts(ants []*Ant) {
+	for _, ant := range ants {
+		if !ant.dead && !slices.Contains(g.ants, ant) {
+			g.ants = append(g.ants, ant)
+		}
+	}
+}
+
+// antsNear returns the living ants within radius of v.
+func (g *Game) antsNear(v vector.Vector, radius float64) []*Ant {
+	near := []*Ant{}
+	for _, ant := range g.ants {
+		if !ant.dead && ant.Distance(v) <= radius {
+			near = append(near, ant)
+		}
+	}
+
+	return near
+}
+
+func (g *Game) editAddHill(v vector.Vector) {
+	g.edit(func() { g.hills.Insert(v) }, func() { g.hills.Remove(v) })
+}
+
+func (g *Game) editRemoveHill(v vector.Vector) {
+	g.edit(func() { g.hills.Remove(v) }, func() { g.hills.Insert(v) })
+}
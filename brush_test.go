@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrushPoints(t *testing.T) {
+	a := vector.Vector{X: 100, Y: 100}
+	b := vector.Vector{X: 200, Y: 150}
+
+	// a point brush paints exactly under the cursor
+	require.Equal(t, []vector.Vector{b}, brushPoints(BrushFreehand, a, b, 0, 10))
+
+	disc := brushPoints(BrushFreehand, a, b, 20, 10)
+	require.NotEmpty(t, disc)
+	for _, p := range disc {
+		require.LessOrEqual(t, p.Distance(b), 20.0)
+	}
+
+	// corners included, either drag direction
+	rect := brushPoints(BrushRectangle, a, b, 0, 10)
+	require.Len(t, rect, 11*6)
+	require.ElementsMatch(t, rect, brushPoints(BrushRectangle, b, a, 0, 10))
+
+	line := brushPoints(BrushLine, a, b, 0, 10)
+	for _, p := range line {
+		require.LessOrEqual(t, vector.DistanceToSegment(p, a, b), 10.0)
+	}
+	require.Contains(t, line, a)
+
+	circle := brushPoints(BrushCircle, a, b, 0, 10)
+	for _, p := range circle {
+		require.LessOrEqual(t, p.Distance(a), a.Distance(b))
+	}
+
+	// clamped to the world and capped
+	all := brushPoints(BrushRectangle, vector.Vector{X: -100, Y: -100}, vector.Vector{X: 2 * GAME_SIZE, Y: 2 * GAME_SIZE}, 0, 1)
+	require.Len(t, all, BRUSH_MAX_POINTS)
+	require.Equal(t, vector.Vector{X: 0, Y: 0}, all[0])
+}
+
+func TestPaint(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+	game.brushFoodAmount = 7
+
+	a := vector.Vector{X: 100, Y: 100}
+	b := vector.Vector{X: 130, Y: 115}
+	game.history.begin()
+	game.paint(CursorModeFood, BrushRectangle, a, b)
+	game.history.end()
+	painted := game.food.Len()
+	require.Equal(t, len(brushPoints(BrushRectangle, a, b, 0, FOOD_SPACING)), painted)
+	for food := range game.food.PointsIter() {
+		require.Equal(t, 7, food.amount)
+	}
+
+	// painting over filled spots adds nothing
+	game.paint(CursorModeFood, BrushRectangle, a, b)
+	require.Equal(t, painted, game.food.Len())
+
+	// the whole stroke is one undo
+	require.True(t, game.history.undo())
+	require.Equal(t, 0, game.food.Len())
+}
+
+func TestHillAndAntTools(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	hill := vector.Vector{X: 100, Y: 100}
+	game.editAddHill(hill)
+	require.True(t, game.atHill(hill))
+
+	game.brushRadius = 20
+	game.spawnAnts(hill, 10)
+	require.Len(t, game.ants, 10)
+	for _, ant := range game.ants {
+		require.LessOrEqual(t, ant.Distance(hill), 20.0+1e-9)
+	}
+
+	game.editRemoveAnts(game.antsNear(hill, 20))
+	require.Empty(t, game.ants)
+
+	require.True(t, game.history.undo())
+	require.Len(t, game.ants, 10)
+	require.True(t, game.history.undo())
+	require.Empty(t, game.ants)
+	require.True(t, game.history.undo())
+	require.False(t, game.atHill(hill))
+}
+
+func TestAntToolsCounts(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	// placed ants aren't born, and every ant dies at most once
+	counts := func(alive, died int) {
+		t.Helper()
+		game.updateColony()
+		require.Len(t, game.ants, alive)
+		require.Equal(t, died, game.deadAntCount)
+		require.Zero(t, game.bornAntCount)
+	}
+
+	game.spawnAnts(vector.Vector{X: 100, Y: 100}, 10)
+	counts(10, 0)
+
+	// some die before the spawn is undone
+	for _, ant := range game.ants[:4] {
+		ant.dead = true
+	}
+	counts(6, 4)
+
+	require.True(t, game.history.undo())
+	counts(0, 4)
+
+	// the dead don't come back
+	require.True(t, game.history.redo())
+	counts(6, 4)
+
+	// same for removed ants that die after being restored
+	game.editRemoveAnts(game.ants[:2])
+	counts(4, 4)
+	require.True(t, game.history.undo())
+	counts(6, 4)
+
+	for _, ant := range game.ants {
+		ant.dead = true
+	}
+	counts(0, 10)
+
+	require.True(t, game.history.redo())
+	require.True(t, game.history.undo())
+	counts(0, 10)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rafibayer/ants-again/util"
+)
+
+// Caste is a class of ant with its own movement and pheromone behavior.
+// scales multiply the colony wide Params, 1.0 behaves like a default ant.
+type Caste struct {
+	Name  string
+	Ratio float64 // share of spawned ants, relative to the other castes
+
+	SpeedScale              float64
+	RotationScale           float64
+	SenseRadiusScale        float64
+	PheromoneSenseProbScale float64 // lower values ignore pheromones more often
+	PheromoneInfluenceScale float64
+	PheromoneDropProbScale  float64
+}
+
+// Default castes if Params has none, every ant behaves like a default ant.
+var DefaultCastes = []Caste{
+	{
+		Name: "worker", Ratio: 1,
+		SpeedScale: 1, RotationScale: 1, SenseRadiusScale: 1,
+		PheromoneSenseProbScale: 1, PheromoneInfluenceScale: 1, PheromoneDropProbScale: 1,
+	},
+}
+
+// ExampleCastes is a mixed colony, the gym tunes its ratios.
+// scenarios/castes.json runs it, TestCastesScenario keeps the two in sync.
+var ExampleCastes = []Caste{
+	{
+		Name: "worker", Ratio: 0.8,
+		SpeedScale: 1, RotationScale: 1, SenseRadiusScale: 1,
+		PheromoneSenseProbScale: 1, PheromoneInfluenceScale: 1, PheromoneDropProbScale: 1,
+	},
+	// fast explorers that mostly ignore existing trails
+	{
+		Name: "scout", Ratio: 0.15,
+		SpeedScale: 1.3, RotationScale: 1.5, SenseRadiusScale: 0.75,
+		PheromoneSenseProbScale: 0.2, PheromoneInfluenceScale: 0.5, PheromoneDropProbScale: 1,
+	},
+	// slow, heavy trail followers
+	{
+		Name: "soldier", Ratio: 0.05,
+		SpeedScale: 0.7, RotationScale: 0.5, SenseRadiusScale: 1.25,
+		PheromoneSenseProbScale: 1, PheromoneInfluenceScale: 1.5, PheromoneDropProbScale: 0.5,
+	},
+}
+
+// validCastes checks castes can be spawned, empty is fine and uses DefaultCastes.
+func validCastes(castes []Caste) error {
+	if len(castes) == 0 {
+		return nil
+	}
+
+	names := map[string]bool{}
+	total := 0.0
+	for i, c := range castes {
+		if c.Name == "" {
+			return fmt.Errorf("caste %d has no name", i)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("caste %q is defined twice", c.Name)
+		}
+		names[c.Name] = true
+
+		for _, scale := range []float64{
+			c.Ratio, c.SpeedScale, c.RotationScale, c.SenseRadiusScale,
+			c.PheromoneSenseProbScale, c.PheromoneInfluenceScale, c.PheromoneDropProbScale,
+		} {
+			if scale < 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+				return fmt.Errorf("caste %q has ratio or scale %v, must be finite and not negative", c.Name, scale)
+			}
+		}
+		total += c.Ratio
+	}
+
+	if total <= 0 {
+		return fmt.Errorf("caste ratios add up to %v, must be positive", total)
+	}
+
+	return nil
+}
+
+func (g *Game) castes() []Caste {
+	if len(g.params.Castes) == 0 {
+		return DefaultCastes
+	}
+
+	return g.params.Castes
+}
+
+func (g *Game) casteOf(ant *Ant) *Caste {
+	castes := g.castes()
+	if ant.caste >= len(castes) {
+		// castes were removed since the ant spawned
+		return &castes[0]
+	}
+
+	return &castes[ant.caste]
+}
+
+// randomCaste picks a caste index weighted by ratio.
+func (g *Game) randomCaste() int {
+	castes := g.castes()
+
+	total := 0.0
+	for _, c := range castes {
+		total += max(0, c.Ratio)
+	}
+
+	x := util.Rand(0, total)
+	for i, c := range castes {
+		x -= max(0, c.Ratio)
+		if x < 0 {
+			return i
+		}
+	}
+
+	return 0
+}
+
+func (g *Game) antSpeed(ant *Ant) float64 {
+	return g.params.AntSpeed * g.casteOf(ant).SpeedScale * g.terrain.Speed(ant.Vector)
+}
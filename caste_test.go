@@ -0,0 +1,79 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCasteRatios(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 100
+	params.Castes = slices.Clone(ExampleCastes)
+	for i := range params.Castes {
+		params.Castes[i].Ratio = 0
+	}
+	params.Castes[1].Ratio = 1
+
+	game := NewGame(&params, nil)
+	for _, ant := range game.ants {
+		require.Equal(t, 1, ant.caste)
+	}
+
+	stats := game.Stats()
+	require.Equal(t, casteStats{name: params.Castes[1].Name, ants: 100}, stats.castes[1])
+}
+
+func TestDefaultCastesNeutral(t *testing.T) {
+	game := NewGame(nil, nil)
+	require.Len(t, game.castes(), 1)
+	require.Equal(t, game.params.AntSpeed, game.antSpeed(game.ants[0]))
+}
+
+func TestCastesScenario(t *testing.T) {
+	scenario, err := LoadScenario("scenarios/castes.json")
+	require.NoError(t, err)
+
+	// castes.json runs ExampleCastes, this keeps the two in sync
+	game := NewGame(nil, scenario)
+	require.Equal(t, ExampleCastes, game.castes())
+
+	// the scenarios castes are the games own
+	game.params.Castes[0].Ratio = 0
+	require.NotZero(t, ExampleCastes[0].Ratio)
+	require.NotZero(t, DefaultCastes[0].Ratio)
+
+	// callers params take precedence over the scenarios
+	params := DefaultParams
+	params.Castes = slices.Clone(DefaultCastes)
+	require.Equal(t, DefaultCastes, NewGame(&params, scenario).castes())
+
+	// and can build on them
+	tuned := scenario.withParams(&DefaultParams)
+	tuned.AntSpeed = 3
+	game = NewGame(tuned, scenario)
+	require.Equal(t, ExampleCastes, game.castes())
+	require.Equal(t, 3.0, game.params.AntSpeed)
+}
+
+func TestInvalidCastes(t *testing.T) {
+	require.NoError(t, validCastes(nil))
+	require.NoError(t, validCastes(ExampleCastes))
+
+	for name, edit := range map[string]func([]Caste){
+		"no name":        func(c []Caste) { c[0].Name = "" },
+		"duplicate name": func(c []Caste) { c[1].Name = c[0].Name },
+		"negative ratio": func(c []Caste) { c[2].Ratio = -0.1 },
+		"negative scale": func(c []Caste) { c[1].SpeedScale = -1 },
+		"zero total": func(c []Caste) {
+			for i := range c {
+				c[i].Ratio = 0
+			}
+		},
+	} {
+		castes := slices.Clone(ExampleCastes)
+		edit(castes)
+		require.Error(t, validCastes(castes), name)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// spawnAnt adds a new foraging ant at pos facing a random direction.
+func (g *Game) spawnAnt(pos vector.Vector) *Ant {
+	g.nextAntID++
+	ant := &Ant{
+		id:              g.nextAntID,
+		Vector:          pos,
+		dir:             vector.Vector{X: util.Rand(-1, 1), Y: util.Rand(-1, 1)},
+		state:           FORAGE,
+		pheromoneStored: g.params.AntPheromoneStart,
+		caste:           g.randomCaste(),
+	}
+
+	g.ants = append(g.ants, ant)
+	return ant
+}
+
+func (g *Game) atHill(v vector.Vector) bool {
+	for range g.hills.RadialSearchIter(v, ANT_HILL_RADIUS) {
+		return true
+	}
+
+	return false
+}
+
+// updateLifecycle ages the ant and spends the energy used walking distance this tick.
+// ants are refueled at the hill, and die if they run out of energy or exceed their lifespan.
+// energy is tracked as distance walked, so turning starvation on mid-run starts every ant full.
+func (g *Game) updateLifecycle(ant *Ant, distance float64) {
+	ant.age++
+
+	if g.params.AntEnergy > 0 {
+		if g.atHill(ant.Vector) {
+			ant.walked = 0
+		}
+
+		ant.walked += distance
+		if ant.walked >= g.params.AntEnergy {
+			ant.dead = true
+		}
+	}
+
+	if g.params.AntLifespan > 0 && ant.age >= g.params.AntLifespan {
+		ant.dead = true
+	}
+}
+
+// updateColony removes dead ants, and spends stored food to spawn new ones at the hills.
+func (g *Game) updateColony() {
+	g.ants = slices.DeleteFunc(g.ants, func(ant *Ant) bool {
+		if ant.dead {
+			g.deadAntCount++
+		}
+		return ant.dead
+	})
+
+	if g.params.ColonySpawnCost <= 0 {
+		return
+	}
+
+	hills := g.hills.Points()
+	if len(hills) == 0 {
+		return
+	}
+
+	for g.storedFood >= g.params.ColonySpawnCost {
+		if g.params.ColonyMaxAnts > 0 && len(g.ants) >= g.params.ColonyMaxAnts {
+			break
+		}
+
+		g.storedFood -= g.params.ColonySpawnCost
+		g.spawnAnt(hills[util.RandInt(0, len(hills))])
+		g.bornAntCount++
+	}
+}
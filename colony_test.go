@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColonyStarvation(t *testing.T) {
+	game := newTestGame(Scenario{}, func(p *Params) {
+		p.AntCount = 10
+		p.AntEnergy = p.AntSpeed * 5
+	})
+
+	// ants can't reach the hill to refuel once they've left it
+	for _, ant := range game.ants {
+		ant.Vector = vector.Vector{X: 100, Y: 100}
+	}
+
+	for range 6 {
+		game.Update()
+	}
+
+	require.Empty(t, game.ants)
+	require.Equal(t, 10, game.deadAntCount)
+}
+
+func TestColonyEnergyBlockedByWall(t *testing.T) {
+	game := newTestGame(Scenario{}, func(p *Params) {
+		p.AntRotation = 0
+		p.AntEnergy = GAME_SIZE
+	})
+
+	// walking into a wall doesn't use any energy
+	game.addWall(vector.Vector{X: 101, Y: 50}, vector.Vector{X: 101, Y: 150})
+	ant := game.spawnAnt(vector.Vector{X: 100, Y: 100})
+	ant.dir = vector.Vector{X: 1, Y: 0}
+
+	game.updateAnts()
+	require.Equal(t, vector.Vector{X: 100, Y: 100}, ant.Vector)
+	require.Zero(t, ant.walked)
+}
+
+func TestColonyEnergyEnabledMidRun(t *testing.T) {
+	game := newTestGame(Scenario{}, func(p *Params) {
+		p.AntCount = 10
+	})
+
+	for _, ant := range game.ants {
+		ant.Vector = vector.Vector{X: 100, Y: 100}
+	}
+	for range 10 {
+		game.Update()
+	}
+
+	// ants that walked before starvation was on start with a full tank
+	game.params.AntEnergy = game.params.AntSpeed * 5
+	for range 3 {
+		game.Update()
+	}
+
+	require.Len(t, game.ants, 10)
+	require.Zero(t, game.deadAntCount)
+}
+
+func TestColonyLifespan(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 10
+	params.AntLifespan = 3
+	game := NewGame(&params, nil)
+
+	game.Update()
+	game.Update()
+	require.Len(t, game.ants, 10)
+
+	game.Update()
+	require.Empty(t, game.ants)
+}
+
+func TestColonyReproduction(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 1
+	params.ColonySpawnCost = 3
+	params.ColonyMaxAnts = 4
+	game := NewGame(&params, nil)
+
+	game.storedFood = 10
+	game.updateColony()
+	require.Len(t, game.ants, 4)
+	require.Equal(t, 3, game.bornAntCount)
+	require.Equal(t, 1, game.storedFood)
+}
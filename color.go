@@ -13,7 +13,13 @@ var (
 	LILAC      = color.RGBA{R: 161, G: 131, B: 192, A: 255}
 	DARK_LILAC = color.RGBA{R: 121, G: 98, B: 143, A: 255}
 
+	RED      = color.RGBA{R: 220, G: 30, B: 30, A: 255}
+	DARK_RED = color.RGBA{R: 150, G: 20, B: 20, A: 255}
+	ORANGE   = color.RGBA{R: 255, G: 140, B: 0, A: 255}
+
 	BROWN = color.RGBA{R: 150, G: 75, B: 0, A: 255}
+
+	YELLOW = color.RGBA{R: 255, G: 220, B: 0, A: 255}
 )
 
 func Fade(c color.RGBA, factor float32) color.RGBA {
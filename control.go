@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// the control API lets scripts drive a running game over HTTP/JSON.
+// handlers never touch the game directly, they queue commands that run on the game loop between ticks.
+//
+//	GET    /state          tick, paused, speed and fast-forward progress
+//	POST   /pause
+//	POST   /resume
+//	POST   /step?ticks=N   run N ticks (default 1) then pause
+//	POST   /speed?x=F      ticks per frame, clamped to [SPEED_MIN, SPEED_MAX]
+//	POST   /fastforward?ticks=N  run N ticks (default FAST_FORWARD_TICKS) as fast as possible
+//	GET    /params
+//	PATCH  /params         partial Params, e.g. {"AntSpeed": 2.5}
+//	GET    /stats          flattened stats, as recorded by Recorder
+//	GET    /snapshot       ants, food, pheromones and the rest of the world
+//	POST   /events         apply an Event now, or schedule it if its tick is in the future
+//	POST   /food           FoodPatch             DELETE /food       Area
+//	POST   /obstacles      {"x", "y"}            DELETE /obstacles  Area
+//	POST   /hills          {"x", "y"}            DELETE /hills      Area
+//	POST   /walls          {"a", "b"}            DELETE /walls      Area
+//	POST   /polygons       [{"x", "y"}, ...]
+//	POST   /hazards        HazardSpec            DELETE /hazards    Area
+//	POST   /predators      {"x", "y"}            DELETE /predators  Area
+//
+// bad requests are answered with 400, a missing hill with 404, stepping past the tick limit with 409,
+// and 503 if the game loop isn't running. bodies are decoded strictly, unknown fields are rejected.
+
+var (
+	errGameStopped = errors.New("game is not running")
+	errTickLimit   = errors.New("tick limit reached")
+)
+
+// statusError is a control error answered with its own HTTP status.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+// errorStatus returns the HTTP status for a control error, errors are the callers fault unless known otherwise.
+func errorStatus(err error) int {
+	var se *statusError
+	switch {
+	case errors.As(err, &se):
+		return se.status
+	case errors.Is(err, errGameStopped):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, errNoHill):
+		return http.StatusNotFound
+	case errors.Is(err, errTickLimit):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// do runs f on the game loop and waits for its result.
+func (g *Game) do(r *http.Request, f func() ([]byte, error)) ([]byte, error) {
+	type result struct {
+		v   []byte
+		err error
+	}
+
+	done := make(chan result, 1)
+	cmd := func() {
+		v, err := f()
+		done <- result{v, err}
+	}
+
+	select {
+	case g.commands <- cmd:
+	case <-r.Context().Done():
+		return nil, errGameStopped
+	}
+
+	res := <-done
+	return res.v, res.err
+}
+
+// runCommands runs the commands queued by the control API.
+func (g *Game) runCommands() {
+	for {
+		select {
+		case cmd := <-g.commands:
+			cmd()
+		default:
+			return
+		}
+	}
+}
+
+func (g *Game) controlHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	// handle registers a command, writing its result as JSON
+	handle := func(pattern string, f func(r *http.Request, body []byte) (any, error)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			// encode on the game loop too, results may point into the game
+			data, err := g.do(r, func() ([]byte, error) {
+				v, err := f(r, body)
+				if err != nil {
+					return nil, err
+				}
+
+				if v == nil {
+					v = g.controlState()
+				}
+
+				data, err := json.Marshal(v)
+				if err != nil {
+					return nil, &statusError{http.StatusInternalServerError, err}
+				}
+				return data, nil
+			})
+			if err != nil {
+				http.Error(w, err.Error(), errorStatus(err))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write(data); err != nil {
+				log.Printf("error writing control response: %v", err)
+			}
+		})
+	}
+
+	// event registers an endpoint that applies the event built from its body
+	event := func(pattern string, build func(e *Event, body []byte) error) {
+		handle(pattern, func(r *http.Request, body []byte) (any, error) {
+			var e Event
+			if err := build(&e, body); err != nil {
+				return nil, fmt.Errorf("invalid body: %w", err)
+			}
+			return nil, g.applyNow(e)
+		})
+	}
+
+	handle("GET /state", func(r *http.Request, body []byte) (any, error) {
+		return nil, nil
+	})
+
+	handle("POST /pause", func(r *http.Request, body []byte) (any, error) {
+		g.setPaused(true)
+		return nil, nil
+	})
+
+	handle("POST /resume", func(r *http.Request, body []byte) (any, error) {
+		g.setPaused(false)
+		return nil, nil
+	})
+
+	handle("POST /step", func(r *http.Request, body []byte) (any, error) {
+		ticks, err := ticksQuery(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		if g.ticksLeft() == 0 {
+			return nil, errTickLimit
+		}
+
+		g.step(ticks)
+		return nil, nil
+	})
+
+	handle("POST /speed", func(r *http.Request, body []byte) (any, error) {
+		s := r.URL.Query().Get("x")
+		speed, err := strconv.ParseFloat(s, 64)
+		if err != nil || speed <= 0 {
+			return nil, fmt.Errorf("invalid speed %q", s)
+		}
+
+		g.setSpeed(speed)
+		return nil, nil
+	})
+
+	handle("POST /fastforward", func(r *http.Request, body []byte) (any, error) {
+		ticks, err := ticksQuery(r, FAST_FORWARD_TICKS)
+		if err != nil {
+			return nil, err
+		}
+		if g.ticksLeft() == 0 {
+			return nil, errTickLimit
+		}
+
+		g.fastForward(ticks)
+		return nil, nil
+	})
+
+	handle("GET /params", func(r *http.Request, body []byte) (any, error) {
+		return g.params, nil
+	})
+
+	handle("PATCH /params", func(r *http.Request, body []byte) (any, error) {
+		// validate against a copy so a bad patch doesn't half apply
+		params := g.params.clone()
+		if err := overlayParams(params, body); err != nil {
+			return nil, err
+		}
+
+		g.params = params
+		return g.params, nil
+	})
+
+	handle("GET /stats", func(r *http.Request, body []byte) (any, error) {
+		stats := map[string]float64{}
+		for _, m := range g.Stats().metrics() {
+			stats[m.name] = m.value
+		}
+		return stats, nil
+	})
+
+	handle("GET /snapshot", func(r *http.Request, body []byte) (any, error) {
+		return g.snapshot(), nil
+	})
+
+	handle("POST /events", func(r *http.Request, body []byte) (any, error) {
+		var e Event
+		if err := decodeStrict(body, &e); err != nil {
+			return nil, fmt.Errorf("invalid event: %w", err)
+		}
+
+		if e.Tick > g.tickCount {
+			if err := e.validate(); err != nil {
+				return nil, err
+			}
+			g.scheduleEvents(append(g.events, e))
+			return nil, nil
+		}
+
+		return nil, g.applyNow(e)
+	})
+
+	event("POST /food", func(e *Event, body []byte) error { return decodeInto(&e.SpawnFood, body) })
+	event("DELETE /food", func(e *Event, body []byte) error { return decodeInto(&e.RemoveFood, body) })
+	event("POST /obstacles", func(e *Event, body []byte) error { return decodeInto(&e.AddObstacle, body) })
+	event("DELETE /obstacles", func(e *Event, body []byte) error { return decodeInto(&e.RemoveObstacles, body) })
+	event("POST /hills", func(e *Event, body []byte) error { return decodeInto(&e.AddHill, body) })
+	event("DELETE /hills", func(e *Event, body []byte) error { return decodeInto(&e.RemoveHills, body) })
+	event("POST /walls", func(e *Event, body []byte) error { return decodeInto(&e.AddWall, body) })
+	event("DELETE /walls", func(e *Event, body []byte) error { return decodeInto(&e.RemoveWalls, body) })
+	event("POST /polygons", func(e *Event, body []byte) error { return decodeStrict(body, &e.AddPolygon) })
+	event("POST /hazards", func(e *Event, body []byte) error { return decodeInto(&e.AddHazard, body) })
+	event("DELETE /hazards", func(e *Event, body []byte) error { return decodeInto(&e.RemoveHazards, body) })
+	event("POST /predators", func(e *Event, body []byte) error { return decodeInto(&e.AddPredator, body) })
+	event("DELETE /predators", func(e *Event, body []byte) error { return decodeInto(&e.RemovePredators, body) })
+
+	return mux
+}
+
+// decodeInto decodes a JSON body into a new value for the event action at dst.
+func decodeInto[T any](dst **T, body []byte) error {
+	var v T
+	if err := decodeStrict(body, &v); err != nil {
+		return err
+	}
+
+	*dst = &v
+	return nil
+}
+
+// ticksQuery parses the ticks query parameter, which must be positive.
+func ticksQuery(r *http.Request, def int) (int, error) {
+	s := r.URL.Query().Get("ticks")
+	if s == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid ticks %q", s)
+	}
+	return n, nil
+}
+
+// applyNow validates and applies an event at the current tick.
+func (g *Game) applyNow(e Event) error {
+	e.Tick = g.tickCount
+	if err := e.validate(); err != nil {
+		return err
+	}
+
+	return g.applyEvent(&e)
+}
+
+type controlState struct {
+	Tick        int     `json:"tick"`
+	Paused      bool    `json:"paused"`
+	Speed       float64 `json:"speed"`
+	FastForward int     `json:"fast_forward"` // ticks left to fast-forward
+}
+
+func (g *Game) controlState() controlState {
+	return controlState{Tick: g.tickCount, Paused: g.paused, Speed: g.speed, FastForward: g.fastForwardTicks}
+}
+
+// Snapshot is the state of the world, as served by the control API.
+type Snapshot struct {
+	controlState
+
+	Ants       []antSnapshot                  `json:"ants"`
+	Food       []foodSnapshot                 `json:"food"`
+	Pheromones map[string][]pheromoneSnapshot `json:"pheromones"` // by type name
+
+	worldSnapshot
+}
+
+// worldSnapshot is everything in the world except ants, food and pheromones.
+type worldSnapshot struct {
+	Hills     []vector.Vector   `json:"hills"`
+	Obstacles []vector.Vector   `json:"obstacles"`
+	Walls     []WallSpec        `json:"walls"` // freestanding walls, polygon edges are in Polygons
+	Polygons  [][]vector.Vector `json:"polygons"`
+	Hazards   []HazardSpec      `json:"hazards"`
+	Predators []vector.Vector   `json:"predators"`
+}
+
+type antSnapshot struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	DirX  float64 `json:"dir_x"`
+	DirY  float64 `json:"dir_y"`
+	State string  `json:"state"`
+	Caste string  `json:"caste"`
+}
+
+type foodSnapshot struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Amount int     `json:"amount"`
+	Patch  string  `json:"patch,omitempty"`
+}
+
+type pheromoneSnapshot struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Amount float32 `json:"amount"`
+}
+
+func (g *Game) snapshot() *Snapshot {
+	s := &Snapshot{
+		controlState:  g.controlState(),
+		Ants:          make([]antSnapshot, 0, len(g.ants)),
+		Food:          make([]foodSnapshot, 0, g.food.Len()),
+		Pheromones:    make(map[string][]pheromoneSnapshot, len(g.pheromones)),
+		worldSnapshot: g.worldSnapshot(),
+	}
+
+	castes := g.castes()
+	for _, ant := range g.ants {
+		s.Ants = append(s.Ants, antSnapshot{
+			X: ant.X, Y: ant.Y,
+			DirX: ant.dir.X, DirY: ant.dir.Y,
+			State: antStates[ant.state],
+			Caste: castes[min(ant.caste, len(castes)-1)].Name,
+		})
+	}
+
+	for food := range g.food.PointsIter() {
+		f := foodSnapshot{X: food.X, Y: food.Y, Amount: food.amount}
+		if food.patch != nil {
+			f.Patch = food.patch.Name
+		}
+		s.Food = append(s.Food, f)
+	}
+
+	for kind, field := range g.pheromones {
+		phers := make([]pheromoneSnapshot, 0, field.Len())
+		for pher := range field.PointsIter() {
+			phers = append(phers, pheromoneSnapshot{X: pher.X, Y: pher.Y, Amount: pher.amount})
+		}
+		s.Pheromones[g.pheromoneTypes[kind].Name] = phers
+	}
+
+	return s
+}
+
+func (g *Game) worldSnapshot() worldSnapshot {
+	s := worldSnapshot{
+		Hills:     g.hills.Points(),
+		Obstacles: make([]vector.Vector, 0, g.obstacles.Len()),
+		Walls:     []WallSpec{},
+		Polygons:  make([][]vector.Vector, 0, len(g.polygons)),
+		Hazards:   make([]HazardSpec, 0, g.hazards.Len()),
+		Predators: make([]vector.Vector, 0, len(g.predators)),
+	}
+
+	for obs := range g.obstacles.PointsIter() {
+		s.Obstacles = append(s.Obstacles, obs.Vector)
+	}
+
+	seen := map[*Wall]bool{}
+	for piece := range g.walls.PointsIter() {
+		if w := piece.wall; w.polygon == nil && !seen[w] {
+			seen[w] = true
+			s.Walls = append(s.Walls, WallSpec{A: w.A, B: w.B})
+		}
+	}
+
+	for _, p := range g.polygons {
+		s.Polygons = append(s.Polygons, p.Vertices)
+	}
+
+	for h := range g.hazards.PointsIter() {
+		s.Hazards = append(s.Hazards, HazardSpec{X: h.X, Y: h.Y, Radius: h.radius, Lethal: h.lethal})
+	}
+
+	for _, p := range g.predators {
+		s.Predators = append(s.Predators, p.Vector)
+	}
+
+	return s
+}
+
+// serveControl serves the control API for the game on addr in the background.
+func serveControl(addr string, g *Game) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening for control: %w", err)
+	}
+
+	go func() {
+		if err := http.Serve(lis, g.controlHandler()); err != nil {
+			log.Printf("control server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("serving control API on http://%s", lis.Addr())
+	return nil
+}
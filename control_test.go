@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// controlTest runs the game loop in the background, serving the control API.
+func controlTest(t *testing.T, game *Game) func(method, path, body string) (int, []byte) {
+	var stop atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !stop.Load() {
+			game.Update()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	server := httptest.NewServer(game.controlHandler())
+	t.Cleanup(func() {
+		server.Close()
+		stop.Store(true)
+		<-done
+	})
+
+	return func(method, path, body string) (int, []byte) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, data
+	}
+}
+
+func TestControlPauseStep(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 10
+	game := NewGame(&params, nil)
+	call := controlTest(t, game)
+
+	var state controlState
+	code, body := call("POST", "/pause", "")
+	require.Equal(t, http.StatusOK, code)
+	require.NoError(t, json.Unmarshal(body, &state))
+	require.True(t, state.Paused)
+
+	paused := state.Tick
+	code, _ = call("POST", "/step?ticks=3", "")
+	require.Equal(t, http.StatusOK, code)
+
+	require.Eventually(t, func() bool {
+		_, body := call("GET", "/state", "")
+		require.NoError(t, json.Unmarshal(body, &state))
+		return state.Tick == paused+3
+	}, time.Second, time.Millisecond)
+
+	// stays paused after stepping
+	time.Sleep(10 * time.Millisecond)
+	_, body = call("GET", "/state", "")
+	require.NoError(t, json.Unmarshal(body, &state))
+	require.Equal(t, paused+3, state.Tick)
+
+	code, _ = call("POST", "/step?ticks=0", "")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, body = call("POST", "/speed?x=4", "")
+	require.Equal(t, http.StatusOK, code)
+	require.NoError(t, json.Unmarshal(body, &state))
+	require.Equal(t, 4.0, state.Speed)
+
+	code, _ = call("POST", "/speed?x=fast", "")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	// fast-forwarding runs while paused
+	code, _ = call("POST", "/fastforward?ticks=20", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Eventually(t, func() bool {
+		_, body := call("GET", "/state", "")
+		require.NoError(t, json.Unmarshal(body, &state))
+		return state.Tick == paused+23 && state.FastForward == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestControlParams(t *testing.T) {
+	game := NewGame(nil, nil)
+	call := controlTest(t, game)
+
+	code, body := call("PATCH", "/params", `{"AntSpeed": 2.5}`)
+	require.Equal(t, http.StatusOK, code)
+
+	var params Params
+	require.NoError(t, json.Unmarshal(body, &params))
+	require.Equal(t, 2.5, params.AntSpeed)
+
+	code, _ = call("PATCH", "/params", `{"AntSpeed": 3, "NotAParam": 1}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	_, body = call("GET", "/params", "")
+	require.NoError(t, json.Unmarshal(body, &params))
+	require.Equal(t, 2.5, params.AntSpeed)
+}
+
+func TestControlWorld(t *testing.T) {
+	game := newTestGame(Scenario{}, func(p *Params) {
+		p.AntCount = 5
+	})
+	call := controlTest(t, game)
+
+	call("POST", "/pause", "")
+
+	code, _ := call("POST", "/food", `{"name": "api", "x": 100, "y": 100, "rows": 2, "cols": 2}`)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/hills", `{"x": 200, "y": 200}`)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/walls", `{"a": {"x": 0, "y": 0}, "b": {"x": 10, "y": 0}}`)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call("POST", "/hazards", `{"x": 300, "y": 300, "radius": 1000}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = call("POST", "/hills", `{"x": 200, "y": 200, "z": 1}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = call("POST", "/events", `{"add_hill": {"x": 1, "y": 1}, "not_an_action": 1}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = call("POST", "/events", `{"move_hill": {"from": {"x": 900, "y": 900}, "to": {"x": 1, "y": 1}}}`)
+	require.Equal(t, http.StatusNotFound, code)
+
+	var snapshot Snapshot
+	_, body := call("GET", "/snapshot", "")
+	require.NoError(t, json.Unmarshal(body, &snapshot))
+	require.Len(t, snapshot.Ants, 5)
+	require.Len(t, snapshot.Food, 4)
+	require.Len(t, snapshot.Hills, 2)
+	require.Len(t, snapshot.Walls, 1)
+	require.Empty(t, snapshot.Hazards)
+
+	code, _ = call("DELETE", "/hills", `{"x": 200, "y": 200, "radius": 1}`)
+	require.Equal(t, http.StatusOK, code)
+
+	var stats map[string]float64
+	_, body = call("GET", "/stats", "")
+	require.NoError(t, json.Unmarshal(body, &stats))
+	require.Contains(t, stats, "patch.api.left") // counted on the next tick
+
+	_, body = call("GET", "/snapshot", "")
+	require.NoError(t, json.Unmarshal(body, &snapshot))
+	require.Len(t, snapshot.Hills, 1)
+}
+
+func TestControlTickLimit(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 0
+	game := NewGame(&params, nil)
+	game.setPaused(true)
+	game.tickLimit = 2
+	call := controlTest(t, game)
+
+	code, _ := call("POST", "/fastforward?ticks=5", "")
+	require.Equal(t, http.StatusOK, code)
+
+	var state controlState
+	require.Eventually(t, func() bool {
+		_, body := call("GET", "/state", "")
+		require.NoError(t, json.Unmarshal(body, &state))
+		return state.Tick == 2
+	}, time.Second, time.Millisecond)
+
+	code, _ = call("POST", "/step", "")
+	require.Equal(t, http.StatusConflict, code)
+	code, _ = call("POST", "/fastforward", "")
+	require.Equal(t, http.StatusConflict, code)
+}
@@ -0,0 +1,373 @@
+// a gym-style reinforcement learning environment, where an external policy steers every ant.
+// the policy sees one Observation per ant and answers with one Action per ant each step.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+const (
+	ENV_MAX_TICKS    = 5 * 60 * TPS
+	ENV_MAX_TURN     = 30.0 // degrees
+	ENV_SENSOR_ANGLE = 45.0 // pheromone sensors are at -ENV_SENSOR_ANGLE, 0 and ENV_SENSOR_ANGLE degrees from the ants heading
+	ENV_LINE_LIMIT   = 64 << 20
+)
+
+var envSensorAngles = []float64{-ENV_SENSOR_ANGLE, 0, ENV_SENSOR_ANGLE}
+
+// EnvConfig configures an Env. zero values use the defaults noted.
+type EnvConfig struct {
+	Params       json.RawMessage `json:"params"`         // partial Params overlaid on DefaultParams, e.g. {"AntCount": 50}
+	MaxTicks     int             `json:"max_ticks"`      // episode length (default: ENV_MAX_TICKS)
+	TicksPerStep int             `json:"ticks_per_step"` // actions apply to the first tick of a step, ants walk straight for the rest (default: 1)
+	MaxTurn      float64         `json:"max_turn"`       // turns are clamped to +- this many degrees (default: ENV_MAX_TURN)
+	Reward       *RewardWeights  `json:"reward"`         // (default: DefaultRewards)
+}
+
+// RewardWeights scale what happened during a step into its reward, shared by all ants.
+type RewardWeights struct {
+	Collected float64 `json:"collected"` // per food returned to a hill
+	Picked    float64 `json:"picked"`    // per food picked up
+	Died      float64 `json:"died"`      // per ant death
+	Step      float64 `json:"step"`      // per step
+}
+
+var DefaultRewards = RewardWeights{Collected: 1, Picked: 0.1, Died: -1}
+
+// Action is a policies decision for one ant, for the first tick of a step.
+type Action struct {
+	Turn float64 `json:"turn"` // degrees
+	Drop bool    `json:"drop"` // drop the pheromone for the ants state
+}
+
+// Observation is what one ant perceives, see observationLabels for the layout of Values.
+// directions are in the ants frame: forward along its heading, right perpendicular to it.
+type Observation struct {
+	ID     int       `json:"id"`
+	Values []float64 `json:"values"`
+}
+
+// StepResult is the outcome of Env.Step.
+type StepResult struct {
+	Observations []Observation `json:"observations"`
+	Reward       float64       `json:"reward"`
+	Done         bool          `json:"done"`
+	Tick         int           `json:"tick"`
+}
+
+// Env runs episodes of the simulation for a policy. it's not safe for concurrent use,
+// and seeds the process wide random source on Reset.
+type Env struct {
+	config   EnvConfig
+	params   *Params
+	scenario *Scenario
+
+	game *Game
+	last envCounters
+}
+
+// envCounters are the game totals rewards are computed from.
+type envCounters struct {
+	collected, picked, died int
+}
+
+func NewEnv(config EnvConfig, scenario *Scenario) (*Env, error) {
+	if scenario == nil {
+		scenario = &DefaultScenario
+	}
+
+	// the configs params take precedence over the scenarios
+	params := scenario.withParams(&DefaultParams)
+	if config.Params != nil {
+		if err := overlayParams(params, config.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.MaxTicks == 0 {
+		config.MaxTicks = ENV_MAX_TICKS
+	}
+	if config.TicksPerStep == 0 {
+		config.TicksPerStep = 1
+	}
+	if config.MaxTurn == 0 {
+		config.MaxTurn = ENV_MAX_TURN
+	}
+	if config.Reward == nil {
+		config.Reward = &DefaultRewards
+	}
+
+	return &Env{config: config, params: params, scenario: scenario}, nil
+}
+
+func observationLabels(kinds []PheromoneKind) []string {
+	labels := []string{
+		"returning", "carrying",
+		"food_forward", "food_right", "food_visible",
+		"hill_forward", "hill_right", "hill_distance",
+	}
+
+	for _, kind := range kinds {
+		for _, angle := range envSensorAngles {
+			labels = append(labels, fmt.Sprintf("pheromone.%s.%g", kind.Name, angle))
+		}
+	}
+
+	return labels
+}
+
+// Reset starts a new episode, seeding the random source so episodes with the same seed and actions repeat.
+func (e *Env) Reset(seed uint64) []Observation {
+	util.Seed(seed)
+
+	e.game = NewGame(e.params, e.scenario)
+	e.game.controlled = true
+	e.last = e.counters()
+	return e.observe()
+}
+
+// Step applies one action per ant, in the order of the last observations, and advances the episode.
+// missing actions leave the ant walking straight.
+func (e *Env) Step(actions []Action) (StepResult, error) {
+	if e.game == nil {
+		return StepResult{}, errors.New("step called before reset")
+	}
+
+	clamped := make([]Action, len(actions))
+	for i, a := range actions {
+		clamped[i] = Action{Turn: util.Clamp(-e.config.MaxTurn, a.Turn, e.config.MaxTurn), Drop: a.Drop}
+	}
+
+	for t := range e.config.TicksPerStep {
+		e.game.actions = nil
+		if t == 0 {
+			e.game.actions = clamped
+		}
+
+		if err := e.game.Update(); err != nil {
+			return StepResult{}, err
+		}
+
+		if e.done() {
+			break
+		}
+	}
+	e.game.actions = nil
+
+	now := e.counters()
+	w := e.config.Reward
+	reward := w.Step +
+		w.Collected*float64(now.collected-e.last.collected) +
+		w.Picked*float64(now.picked-e.last.picked) +
+		w.Died*float64(now.died-e.last.died)
+	e.last = now
+
+	return StepResult{
+		Observations: e.observe(),
+		Reward:       reward,
+		Done:         e.done(),
+		Tick:         e.game.tickCount,
+	}, nil
+}
+
+func (e *Env) counters() envCounters {
+	return envCounters{collected: e.game.collectedFood, picked: e.game.pickedFoodCount, died: e.game.deadAntCount}
+}
+
+// done ends the episode at MaxTicks, or once there are no ants or no food left.
+func (e *Env) done() bool {
+	g := e.game
+	return g.tickCount >= e.config.MaxTicks || len(g.ants) == 0 || (g.tickCount > 0 && g.remainingFoodCount == 0)
+}
+
+func (e *Env) observe() []Observation {
+	obs := make([]Observation, len(e.game.ants))
+	for i, ant := range e.game.ants {
+		obs[i] = Observation{ID: ant.id, Values: e.game.observe(ant)}
+	}
+
+	return obs
+}
+
+// observe describes the world around the ant, see observationLabels.
+func (g *Game) observe(ant *Ant) []float64 {
+	senseRadius := g.senseRadius(ant)
+
+	forward := ant.dir.Normalize()
+	right := forward.Perpendicular()
+	local := func(v vector.Vector) (float64, float64) {
+		return v.Dot(forward), v.Dot(right)
+	}
+
+	values := make([]float64, 0, 8+len(g.pheromoneTypes)*len(envSensorAngles))
+
+	returning := 0.0
+	if ant.state == RETURN {
+		returning = 1
+	}
+	values = append(values, returning, float64(ant.carrying))
+
+	// nearest food with something left
+	nearest := math.Inf(1)
+	toFood := vector.ZERO
+	for food := range g.food.RadialSearchIter(ant.Vector, senseRadius) {
+		if d := ant.Distance(*food.Vector); food.amount > 0 && d < nearest {
+			nearest = d
+			toFood = food.Sub(ant.Vector)
+		}
+	}
+
+	visible := 0.0
+	if !math.IsInf(nearest, 1) {
+		visible = 1
+	}
+	foodForward, foodRight := local(toFood.Mul(1 / senseRadius))
+	values = append(values, foodForward, foodRight, visible)
+
+	// nearest hill, ants always know their way home
+	nearest = math.Inf(1)
+	toHill := vector.ZERO
+	for hill := range g.hills.PointsIter() {
+		if d := ant.Distance(hill); d < nearest {
+			nearest = d
+			toHill = hill.Sub(ant.Vector)
+		}
+	}
+
+	hillForward, hillRight, hillDistance := 0.0, 0.0, 0.0
+	if !math.IsInf(nearest, 1) {
+		if toHill.Magnitude() > 0 {
+			hillForward, hillRight = local(toHill.Normalize())
+		}
+		hillDistance = nearest / GAME_SIZE
+	}
+	values = append(values, hillForward, hillRight, hillDistance)
+
+	// sensors ahead of the ant, each covering half the sense radius
+	for _, field := range g.pheromones {
+		for _, angle := range envSensorAngles {
+			center := ant.Add(forward.Rotate(angle).Mul(senseRadius / 2))
+
+			total := 0.0
+			for pher := range field.RadialSearchIter(center, senseRadius/2) {
+				total += float64(pher.amount)
+			}
+			values = append(values, total)
+		}
+	}
+
+	return values
+}
+
+// action returns the policies action for the i-th ant this tick.
+func (g *Game) action(i int) Action {
+	if i < len(g.actions) {
+		return g.actions[i]
+	}
+
+	return Action{}
+}
+
+// envRequest is a line of the env protocol, one JSON object per line in each direction.
+//
+//	{"cmd": "spec"}                          -> {"labels": [...], "max_turn": 30}
+//	{"cmd": "reset", "seed": 1}              -> {"observations": [...]}
+//	{"cmd": "step", "actions": [{"turn": 5, "drop": true}, ...]} -> StepResult
+//	{"cmd": "close"}
+//
+// failed requests are answered with {"error": "..."}.
+type envRequest struct {
+	Cmd     string   `json:"cmd"`
+	Seed    uint64   `json:"seed"`
+	Actions []Action `json:"actions"`
+}
+
+type envSpec struct {
+	Labels       []string `json:"labels"`
+	MaxTurn      float64  `json:"max_turn"`
+	MaxTicks     int      `json:"max_ticks"`
+	TicksPerStep int      `json:"ticks_per_step"`
+}
+
+type envError struct {
+	Error string `json:"error"`
+}
+
+// serveEnv answers env protocol requests from r on w until close or EOF.
+func serveEnv(env *Env, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), ENV_LINE_LIMIT)
+	enc := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		var req envRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			if err := enc.Encode(envError{Error: fmt.Sprintf("invalid request: %v", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var resp any
+		switch req.Cmd {
+		case "spec":
+			resp = envSpec{
+				Labels:       observationLabels(env.scenario.pheromoneTypes()),
+				MaxTurn:      env.config.MaxTurn,
+				MaxTicks:     env.config.MaxTicks,
+				TicksPerStep: env.config.TicksPerStep,
+			}
+		case "reset":
+			resp = StepResult{Observations: env.Reset(req.Seed)}
+		case "step":
+			result, err := env.Step(req.Actions)
+			if err != nil {
+				resp = envError{Error: err.Error()}
+			} else {
+				resp = result
+			}
+		case "close":
+			return nil
+		default:
+			resp = envError{Error: fmt.Sprintf("unknown cmd %q", req.Cmd)}
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// listenEnv serves the env protocol to one TCP client at a time.
+func listenEnv(env *Env, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening for env: %w", err)
+	}
+	defer lis.Close()
+
+	log.Printf("serving env on %s", lis.Addr())
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+
+		if err := serveEnv(env, conn, conn); err != nil {
+			log.Printf("env client error: %v", err)
+		}
+		conn.Close()
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvDeterministic(t *testing.T) {
+	env, err := NewEnv(EnvConfig{Params: json.RawMessage(`{"AntCount": 20}`)}, nil)
+	require.NoError(t, err)
+
+	run := func() []StepResult {
+		obs := env.Reset(7)
+		require.Len(t, obs, 20)
+		require.Len(t, obs[0].Values, len(observationLabels(DefaultPheromones)))
+
+		results := []StepResult{}
+		for range 20 {
+			actions := make([]Action, len(obs))
+			for i := range actions {
+				actions[i] = Action{Turn: 100, Drop: i%2 == 0} // clamped to MaxTurn
+			}
+
+			result, err := env.Step(actions)
+			require.NoError(t, err)
+			results = append(results, result)
+			obs = result.Observations
+		}
+		return results
+	}
+
+	require.Equal(t, run(), run())
+}
+
+func TestEnvReward(t *testing.T) {
+	env, err := NewEnv(EnvConfig{
+		Params: json.RawMessage(`{"AntCount": 1}`),
+		Reward: &RewardWeights{Picked: 2, Step: -0.5},
+	}, &Scenario{
+		Hills: []vector.Vector{{X: 500, Y: 500}},
+		Food:  []FoodPatch{{X: 100, Y: 100, Rows: 1, Cols: 1}},
+	})
+	require.NoError(t, err)
+
+	_, err = env.Step(nil)
+	require.Error(t, err)
+
+	env.Reset(1)
+
+	// put the ant on the food
+	env.game.ants[0].Vector = vector.Vector{X: 100, Y: 100}
+	obs := env.observe()
+	require.Equal(t, 1.0, obs[0].Values[4]) // food_visible
+
+	result, err := env.Step(nil)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, result.Reward)
+	require.Equal(t, 1.0, result.Observations[0].Values[0]) // returning
+}
+
+func TestEnvDropNeedsPheromone(t *testing.T) {
+	env, err := NewEnv(EnvConfig{Params: json.RawMessage(`{"AntCount": 1}`)}, nil)
+	require.NoError(t, err)
+	env.Reset(1)
+
+	// out of pheromone, dropping does nothing
+	env.game.ants[0].pheromoneStored = 0
+	_, err = env.Step([]Action{{Drop: true}})
+	require.NoError(t, err)
+
+	require.Zero(t, env.game.ants[0].pheromoneStored)
+	require.Zero(t, env.game.pheromones[PheromoneForaging].Len())
+}
+
+func TestEnvProtocol(t *testing.T) {
+	env, err := NewEnv(EnvConfig{Params: json.RawMessage(`{"AntCount": 3}`), MaxTicks: 2}, nil)
+	require.NoError(t, err)
+
+	requests := strings.Join([]string{
+		`{"cmd": "spec"}`,
+		`{"cmd": "step"}`,
+		`{"cmd": "reset", "seed": 3}`,
+		`{"cmd": "step", "actions": [{"turn": 5, "drop": true}]}`,
+		`{"cmd": "step"}`,
+		`{"cmd": "nope"}`,
+		`{"cmd": "close"}`,
+		`{"cmd": "spec"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	require.NoError(t, serveEnv(env, strings.NewReader(requests), &out))
+
+	lines := []map[string]any{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+
+	// nothing is answered after close
+	require.Len(t, lines, 6)
+	require.Len(t, lines[0]["labels"], len(observationLabels(DefaultPheromones)))
+	require.Contains(t, lines[1], "error")
+	require.Len(t, lines[2]["observations"], 3)
+	require.Equal(t, false, lines[3]["done"])
+	require.Equal(t, true, lines[4]["done"])
+	require.Contains(t, lines[5], "error")
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rafibayer/ants-again/spatial"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// Event is a scripted change to the world at a given tick.
+// exactly one action should be set.
+type Event struct {
+	Tick int `json:"tick"`
+
+	SpawnFood   *FoodPatch      `json:"spawn_food,omitempty"`
+	RemoveFood  *Area           `json:"remove_food,omitempty"`
+	RemovePatch string          `json:"remove_patch,omitempty"` // removes all food in the named patch
+	AddWall     *WallSpec       `json:"add_wall,omitempty"`
+	AddPolygon  []vector.Vector `json:"add_polygon,omitempty"`
+	RemoveWalls *Area           `json:"remove_walls,omitempty"` // removes walls and polygons passing through the area
+	MoveHill    *HillMove       `json:"move_hill,omitempty"`
+	SetParams   json.RawMessage `json:"set_params,omitempty"` // partial Params as JSON, e.g. {"AntSpeed": 2.5}
+
+	AddObstacle     *vector.Vector `json:"add_obstacle,omitempty"`
+	RemoveObstacles *Area          `json:"remove_obstacles,omitempty"`
+	AddHill         *vector.Vector `json:"add_hill,omitempty"`
+	RemoveHills     *Area          `json:"remove_hills,omitempty"`
+	AddHazard       *HazardSpec    `json:"add_hazard,omitempty"`
+	RemoveHazards   *Area          `json:"remove_hazards,omitempty"` // removes hazards centered in the area
+	AddPredator     *vector.Vector `json:"add_predator,omitempty"`
+	RemovePredators *Area          `json:"remove_predators,omitempty"`
+}
+
+var errNoHill = errors.New("no hill")
+
+// Area is a circle in world space.
+type Area struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+}
+
+// HillMove moves the hill within ANT_HILL_RADIUS of From to To.
+type HillMove struct {
+	From vector.Vector `json:"from"`
+	To   vector.Vector `json:"to"`
+}
+
+func (a *Area) center() vector.Vector {
+	return vector.Vector{X: a.X, Y: a.Y}
+}
+
+func (e *Event) validate() error {
+	actions := 0
+	for _, set := range []bool{
+		e.SpawnFood != nil,
+		e.RemoveFood != nil,
+		e.RemovePatch != "",
+		e.AddWall != nil,
+		e.AddPolygon != nil,
+		e.RemoveWalls != nil,
+		e.MoveHill != nil,
+		e.SetParams != nil,
+		e.AddObstacle != nil,
+		e.RemoveObstacles != nil,
+		e.AddHill != nil,
+		e.RemoveHills != nil,
+		e.AddHazard != nil,
+		e.RemoveHazards != nil,
+		e.AddPredator != nil,
+		e.RemovePredators != nil,
+	} {
+		if set {
+			actions++
+		}
+	}
+
+	if actions != 1 {
+		return fmt.Errorf("event at tick %d has %d actions, exactly 1 is required", e.Tick, actions)
+	}
+
+	if e.AddPolygon != nil && len(e.AddPolygon) < 3 {
+		return fmt.Errorf("event at tick %d: polygon has %d vertices, at least 3 are required", e.Tick, len(e.AddPolygon))
+	}
+
+	if e.AddHazard != nil && (e.AddHazard.Radius <= 0 || e.AddHazard.Radius > HAZARD_MAX_RADIUS) {
+		return fmt.Errorf("event at tick %d: hazard radius must be in (0, %v]", e.Tick, HAZARD_MAX_RADIUS)
+	}
+
+	if e.SetParams != nil {
+		if err := overlayParams(&Params{}, e.SetParams); err != nil {
+			return fmt.Errorf("event at tick %d: %w", e.Tick, err)
+		}
+	}
+
+	return nil
+}
+
+// overlayParams sets the fields present in patch on params.
+func overlayParams(params *Params, patch json.RawMessage) error {
+	if err := decodeStrict(patch, params); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+
+	return params.validate()
+}
+
+// decodeStrict decodes JSON into v, rejecting unknown fields like scenario files do.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// scheduleEvents queues events to be applied as their ticks arrive.
+func (g *Game) scheduleEvents(events []Event) {
+	g.events = slices.Clone(events)
+	slices.SortStableFunc(g.events, func(a, b Event) int {
+		return a.Tick - b.Tick
+	})
+}
+
+// updateEvents applies all events due by the current tick.
+func (g *Game) updateEvents() error {
+	for len(g.events) > 0 && g.events[0].Tick <= g.tickCount {
+		event := g.events[0]
+		g.events = g.events[1:]
+
+		if err := g.applyEvent(&event); err != nil {
+			return fmt.Errorf("error applying event at tick %d: %w", event.Tick, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *Game) applyEvent(e *Event) error {
+	switch {
+	case e.SpawnFood != nil:
+		patch := *e.SpawnFood
+		if patch.Name == "" {
+			patch.Name = fmt.Sprintf("event %d", e.Tick)
+		}
+		g.insertFoodPatch(patch)
+
+	case e.RemoveFood != nil:
+		for _, food := range g.food.RadialSearch(e.RemoveFood.center(), e.RemoveFood.Radius) {
+			spatial.RemoveSame(g.food, food)
+		}
+
+	case e.RemovePatch != "":
+		toRemove := make([]*Food, 0)
+		for food := range g.food.PointsIter() {
+			if food.patch != nil && food.patch.Name == e.RemovePatch {
+				toRemove = append(toRemove, food)
+			}
+		}
+
+		for _, r := range toRemove {
+			spatial.RemoveSame(g.food, r)
+		}
+
+	case e.AddWall != nil:
+		g.addWall(e.AddWall.A, e.AddWall.B)
+
+	case e.AddPolygon != nil:
+		g.addPolygon(slices.Clone(e.AddPolygon))
+
+	case e.RemoveWalls != nil:
+		for _, w := range g.wallsNear(e.RemoveWalls.center(), e.RemoveWalls.Radius) {
+			g.removeWall(w)
+		}
+
+	case e.MoveHill != nil:
+		hills := g.hills.RadialSearch(e.MoveHill.From, ANT_HILL_RADIUS)
+		if len(hills) == 0 {
+			return fmt.Errorf("%w near %v", errNoHill, e.MoveHill.From)
+		}
+
+		g.hills.Remove(hills[0])
+		g.hills.Insert(e.MoveHill.To)
+
+	case e.SetParams != nil:
+		return overlayParams(g.params, e.SetParams)
+
+	case e.AddObstacle != nil:
+		g.obstacles.Insert(&Obstacle{Vector: *e.AddObstacle})
+
+	case e.RemoveObstacles != nil:
+		for _, obs := range g.obstacles.RadialSearch(e.RemoveObstacles.center(), e.RemoveObstacles.Radius) {
+			spatial.RemoveSame(g.obstacles, obs)
+		}
+
+	case e.AddHill != nil:
+		g.hills.Insert(*e.AddHill)
+
+	case e.RemoveHills != nil:
+		for _, hill := range g.hills.RadialSearch(e.RemoveHills.center(), e.RemoveHills.Radius) {
+			g.hills.Remove(hill)
+		}
+
+	case e.AddHazard != nil:
+		g.addHazard(vector.Vector{X: e.AddHazard.X, Y: e.AddHazard.Y}, e.AddHazard.Radius, e.AddHazard.Lethal)
+
+	case e.RemoveHazards != nil:
+		for _, h := range g.hazards.RadialSearch(e.RemoveHazards.center(), e.RemoveHazards.Radius) {
+			spatial.RemoveSame(g.hazards, h)
+		}
+
+	case e.AddPredator != nil:
+		g.addPredator(*e.AddPredator)
+
+	case e.RemovePredators != nil:
+		center := e.RemovePredators.center()
+		g.predators = slices.DeleteFunc(g.predators, func(p *Predator) bool {
+			return p.Distance(center) <= e.RemovePredators.Radius
+		})
+	}
+
+	return nil
+}
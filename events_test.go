@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioFiles(t *testing.T) {
+	paths, err := filepath.Glob("scenarios/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		scenario, err := LoadScenario(path)
+		require.NoError(t, err, path)
+
+		params := DefaultParams
+		params.AntCount = 10
+		game := NewGame(&params, scenario)
+		for range 10 {
+			require.NoError(t, game.Update(), path)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 0
+	game := NewGame(&params, &Scenario{
+		Hills: []vector.Vector{{X: 500, Y: 500}},
+		Events: []Event{
+			{Tick: 2, RemovePatch: "a"},
+			{Tick: 1, SpawnFood: &FoodPatch{Name: "a", X: 100, Y: 100, Rows: 2, Cols: 2}},
+			{Tick: 1, AddWall: &WallSpec{A: vector.Vector{X: 0, Y: 0}, B: vector.Vector{X: 10, Y: 0}}},
+			{Tick: 3, RemoveWalls: &Area{X: 5, Y: 0, Radius: 1}},
+			{Tick: 3, MoveHill: &HillMove{From: vector.Vector{X: 505, Y: 500}, To: vector.Vector{X: 100, Y: 100}}},
+			{Tick: 3, SetParams: json.RawMessage(`{"AntSpeed": 4.5}`)},
+		},
+	})
+
+	step := func() {
+		t.Helper()
+		require.NoError(t, game.Update())
+	}
+
+	step() // tick 0
+	require.Equal(t, 0, game.food.Len())
+
+	step() // tick 1
+	require.Equal(t, 4, game.food.Len())
+	require.Equal(t, 1, game.walls.Len())
+
+	step() // tick 2
+	require.Equal(t, 0, game.food.Len())
+
+	step() // tick 3
+	require.Equal(t, 0, game.walls.Len())
+	require.Equal(t, []vector.Vector{{X: 100, Y: 100}}, game.hills.Points())
+	require.Equal(t, 4.5, game.params.AntSpeed)
+	require.Equal(t, DefaultParams.AntSpeed, params.AntSpeed, "games don't share params")
+}
+
+func TestScenarioInvalidEvents(t *testing.T) {
+	for _, events := range []string{
+		`[{"tick": 1}]`,
+		`[{"tick": 1, "remove_patch": "a", "remove_food": {"x": 1, "y": 1, "radius": 1}}]`,
+		`[{"tick": 1, "set_params": {"NotAParam": 1}}]`,
+		`[{"tick": 1, "set_params": {"WallModeIndex": 2}}]`,
+		`[{"tick": 1, "set_params": {"DepositModeIndex": -1}}]`,
+		`[{"tick": 1, "set_params": {"Castes": [{"Name": "a", "Ratio": -1}]}}]`,
+		`[{"tick": 1, "add_polygon": [{"x": 1, "y": 1}]}]`,
+	} {
+		path := filepath.Join(t.TempDir(), "scenario.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"hills": [{"x": 1, "y": 1}], "events": `+events+`}`), 0o644))
+
+		_, err := LoadScenario(path)
+		require.Error(t, err, events)
+	}
+}
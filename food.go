@@ -1,26 +1,65 @@
 package main
 
-import "github.com/rafibayer/ants-again/vector"
+import (
+	"github.com/rafibayer/ants-again/spatial"
+	"github.com/rafibayer/ants-again/vector"
+)
 
 type Food struct {
 	// Position
 	*vector.Vector
 
-	amount int
+	amount   int
+	capacity int     // amount regrowth stops at
+	quality  float32 // better food recruits more ants, 1.0 is normal food
+
+	regrowth float64 // amount regrown per tick, 0 for finite food that is removed once depleted
+	regrown  float64 // fractional regrowth not yet added to amount
+
+	exhaustedTicks int // ticks since finite food ran out
+
+	patch *Patch // patch the food belongs to, nil if placed individually
+}
+
+// Patch is a named group of food, tracked for per-patch stats.
+type Patch struct {
+	Name string
+
+	left      int
+	collected int
 }
 
 func (g *Game) updateFood() {
 	g.remainingFoodCount = 0
+	for _, patch := range g.patches {
+		patch.left = 0
+	}
 
 	toRemove := make([]*Food, 0)
 	for food := range g.food.PointsIter() {
+		if food.regrowth > 0 && food.amount < food.capacity {
+			food.regrown += food.regrowth
+			grown := int(food.regrown)
+			food.amount = min(food.capacity, food.amount+grown)
+			food.regrown -= float64(grown)
+		}
+
 		g.remainingFoodCount += food.amount
-		if food.amount <= 0 {
-			toRemove = append(toRemove, food)
+		if food.patch != nil {
+			food.patch.left += food.amount
+		}
+
+		// regrowing food stays around to be replenished. with repellent enabled,
+		// finite food lingers for a while so ants can discover it's gone
+		if food.amount <= 0 && food.regrowth == 0 {
+			food.exhaustedTicks++
+			if g.params.AntRepellentStart == 0 || food.exhaustedTicks > FOOD_EXHAUSTED_TICKS {
+				toRemove = append(toRemove, food)
+			}
 		}
 	}
 
 	for _, r := range toRemove {
-		g.food.Remove(r)
+		spatial.RemoveSame(g.food, r)
 	}
 }
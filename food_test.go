@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoodRegrowth(t *testing.T) {
+	game := newTestGame(Scenario{
+		Food: []FoodPatch{
+			{Name: "regrowing", X: 100, Y: 100, Rows: 1, Cols: 2, Amount: 1, Capacity: 3, Regrowth: 0.5},
+			{Name: "finite", X: 200, Y: 200, Rows: 1, Cols: 1, Amount: 1},
+		},
+	}, nil)
+
+	for food := range game.food.PointsIter() {
+		food.amount = 0
+	}
+
+	game.updateFood()
+	require.Equal(t, 2, game.food.Len(), "finite food is removed once depleted")
+	require.Equal(t, 0, game.remainingFoodCount)
+
+	for range 20 {
+		game.updateFood()
+	}
+
+	require.Equal(t, 6, game.remainingFoodCount, "regrowth stops at capacity")
+	require.Equal(t, []patchStats{{name: "regrowing", left: 6}, {name: "finite"}}, game.Stats().patches)
+}
+
+func TestExhaustedFoodLingers(t *testing.T) {
+	game := newTestGame(Scenario{
+		Food: []FoodPatch{{X: 100, Y: 100, Rows: 1, Cols: 1}},
+	}, func(p *Params) {
+		p.AntRepellentStart = 5
+	})
+
+	for food := range game.food.PointsIter() {
+		food.amount = 0
+	}
+
+	// with repellent enabled, ants get a chance to find out it's gone
+	game.updateFood()
+	require.Equal(t, 1, game.food.Len())
+
+	for range FOOD_EXHAUSTED_TICKS {
+		game.updateFood()
+	}
+
+	require.Zero(t, game.food.Len())
+}
+
+func TestExhaustedFoodSharedPosition(t *testing.T) {
+	game := newTestGame(Scenario{}, func(p *Params) {
+		p.AntRepellentStart = 5
+	})
+
+	// live food, and exhausted food dropped on the same spot
+	live := &Food{amount: 5, capacity: 5, quality: 1, Vector: &vector.Vector{X: 100, Y: 100}}
+	game.food.Insert(live)
+	game.food.Insert(&Food{capacity: 5, quality: 1, exhaustedTicks: FOOD_EXHAUSTED_TICKS, Vector: &vector.Vector{X: 100, Y: 100}})
+
+	game.updateFood()
+	require.Equal(t, 1, game.food.Len())
+	require.Same(t, live, game.food.Points()[0])
+}
@@ -2,11 +2,12 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/ebitengine/debugui"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/rafibayer/ants-again/spatial"
-	"github.com/rafibayer/ants-again/util"
 	"github.com/rafibayer/ants-again/vector"
 )
 
@@ -18,18 +19,27 @@ const (
 	ANT_FOOD_RADIUS = GAME_SIZE / 200.0 // radius in which an ant will pick up food
 	ANT_HILL_RADIUS = GAME_SIZE / 30.0  // radius in which an ant will return to hill
 
-	FOOD_START = 50 // starting amount per food
+	FOOD_START           = 50       // starting amount per food
+	FOOD_EXHAUSTED_TICKS = 30 * TPS // ticks depleted food remains before being removed
 )
 
-// spatial hash densities
+// spatial grid densities
 // these are fairly import perf knobs, especially for pheromones.
-// if these are missized the cells either get too crowded or we have to search too many of them
+// if these are missized the cells either get too crowded or we have to search too many of them.
+// the world is bounded, so we use dense grids rather than hashes: cheaper cell probes,
+// and deterministic iteration order.
 const (
-	PHEROMONE_HASH_CELL_SIZE = GAME_SIZE / 20.0
-	FOOD_HASH_CELL_SIZE      = GAME_SIZE / 20.0
-	HILL_HASH_CELL_SIZE      = GAME_SIZE / 5.0
+	PHEROMONE_CELL_SIZE = GAME_SIZE / 20.0
+	FOOD_CELL_SIZE      = GAME_SIZE / 20.0
+	HILL_CELL_SIZE      = GAME_SIZE / 5.0
 
-	OBSTACLE_HASH_CELL_SIZE = GAME_SIZE / 100.0
+	OBSTACLE_CELL_SIZE = GAME_SIZE / 100.0
+	WALL_CELL_SIZE     = GAME_SIZE / 50.0
+)
+
+const (
+	WALL_PIECE_LENGTH = GAME_SIZE / 100.0 // walls are split into pieces of at most this length
+	WALL_WIDTH        = 3.0               // drawn width of walls
 )
 
 type Game struct {
@@ -47,65 +57,96 @@ type Game struct {
 	world *ebiten.Image
 	px    []byte // pixel buffer: width * height * 4 (R,G,B,A)
 
-	ants []*Ant
-	food spatial.Spatial[*Food]
+	background []byte // terrain pixels the pixel buffer is reset to, built on first draw
+
+	ants    []*Ant
+	food    spatial.Spatial[*Food]
+	patches []*Patch
 
 	obstacles spatial.Spatial[*Obstacle]
+	walls     spatial.Spatial[*wallPiece]
+	polygons  []*Polygon
+
+	wallStart    *vector.Vector  // start of the wall being drawn, if any
+	polygonDraft []vector.Vector // vertices of the polygon being drawn
+	cursor       vector.Vector   // cursor position in world space
+	history      History         // mouse edits, for undo
+	shapeStart   *vector.Vector  // start of the brush shape being dragged, if any
+
+	brushShapeIndex int     // see brushShapes
+	brushRadius     float64 // 0 paints single points
+	brushFoodAmount int     // amount per food painted
+	brushAntCount   int     // ants spawned per click
+
+	selected       *Ant // shown in the inspector, nil if none
+	followSelected bool // keep the camera centered on the selected ant
+
+	events []Event // scheduled events, ordered by tick
+
+	script      *scriptState // scenario script, nil if the scenario has none
+	scriptScore float64      // last custom score from the script
+	scored      bool         // whether the script defines a score
+
+	recorder   *Recorder // stats time series, nil if not recording
+	recordPath string    // where the ui exports the recording
+
+	metrics    *Metrics // live metrics, nil if not exported
+	metricsSim string   // sim label for this games metrics
+
+	streamer *Streamer // websocket viewers, nil if not streaming
+
+	controlled bool     // ants turn and drop pheromones as told by actions, see env.go
+	actions    []Action // for this tick, indexed like ants
+	nextAntID  int
+
+	paused            bool
+	stepTicks         int         // ticks left to run while paused
+	speed             float64     // ticks per frame, see ticksThisFrame
+	tickDebt          float64     // fractional ticks carried to the next frame
+	fastForwardTicks  int         // ticks left to fast-forward
+	fastForwardLength int         // ticks fast-forwarded by the hotkey and ui
+	tickLimit         int         // ticks the game stops at, 0 for no limit. see runHeadless
+	commands          chan func() // run on the game loop, see control.go
+
+	terrain *Terrain
+
+	hazards   spatial.Spatial[*Hazard]
+	predators []*Predator
 
 	hills         spatial.Spatial[vector.Vector]
 	collectedFood int
 
-	foragingPheromone  spatial.Spatial[*Pheromone]
-	returningPheromone spatial.Spatial[*Pheromone]
+	// indexed by caste
+	casteCollected map[int]int
+	storedFood     int // collected food not yet spent on new ants
+
+	pheromoneTypes []PheromoneKind               // from the scenario, fixed for the game
+	pheromones     []spatial.Spatial[*Pheromone] // indexed by PheromoneType
 
 	foragingAntCount   int
 	returningAntCount  int
 	remainingFoodCount int
+	pickedFoodCount    int // food picked up, collected food is counted when it reaches a hill
+	bornAntCount       int
+	deadAntCount       int
+	killedAntCount     int // ants killed by hazards and predators, included in deadAntCount
 }
 
-func NewGame(params *Params) *Game {
-	if params == nil {
-		params = &DefaultParams
-	}
-
-	ants := []*Ant{}
-	food := spatial.NewHash[*Food](FOOD_HASH_CELL_SIZE)
-	hills := spatial.NewHash[vector.Vector](HILL_HASH_CELL_SIZE)
-
-	for range ANTS {
-		ants = append(ants, &Ant{
-			Vector:          vector.Vector{X: GAME_SIZE / 2, Y: GAME_SIZE / 2},
-			dir:             vector.Vector{X: util.Rand(-1, 1), Y: util.Rand(-1, 1)},
-			state:           FORAGE,
-			pheromoneStored: params.AntPheromoneStart,
-		})
+// NewGame creates a game running scenario with params as given.
+// nil params are DefaultParams with the scenarios params on top, see Scenario.withParams.
+func NewGame(params *Params, scenario *Scenario) *Game {
+	if scenario == nil {
+		scenario = &DefaultScenario
 	}
 
-	for r := range 30 {
-		for c := range 10 {
-			// top left
-			food.Insert(&Food{
-				Vector: &vector.Vector{X: GAME_SIZE/5 + float64(r)*1.5, Y: GAME_SIZE/5 + float64(c)*1.5},
-				amount: FOOD_START,
-			})
-
-			// mid right
-			food.Insert(&Food{
-				Vector: &vector.Vector{X: GAME_SIZE*(5.0/6.0) + float64(r)*1.5, Y: GAME_SIZE/2 + float64(c)*1.5},
-				amount: FOOD_START,
-			})
-
-			// far bottom right
-			food.Insert(&Food{
-				Vector: &vector.Vector{X: GAME_SIZE*(9.0/10.0) + float64(r)*1.5, Y: GAME_SIZE*(9.0/10.0) + float64(c)*1.5},
-				amount: FOOD_START,
-			})
-		}
+	if params == nil {
+		params = scenario.withParams(&DefaultParams)
+	} else {
+		// games can change their params (ui, events), don't share them
+		params = params.clone()
 	}
 
-	hills.Insert(vector.Vector{X: GAME_SIZE / 2, Y: GAME_SIZE / 2})
-
-	return &Game{
+	g := &Game{
 		params: params,
 
 		frameCount: 0,
@@ -118,18 +159,32 @@ func NewGame(params *Params) *Game {
 		world: ebiten.NewImage(GAME_SIZE, GAME_SIZE),
 		px:    make([]byte, GAME_SIZE*GAME_SIZE*4), // pheromone buffer: 4 bytes per pixel (R,G,B,A)
 
-		ants:      ants,
-		food:      food,
-		hills:     hills,
-		obstacles: spatial.NewHash[*Obstacle](OBSTACLE_HASH_CELL_SIZE),
+		commands:          make(chan func()),
+		speed:             1,
+		brushFoodAmount:   FOOD_START,
+		brushAntCount:     BRUSH_ANT_COUNT,
+		fastForwardLength: FAST_FORWARD_TICKS,
+
+		ants:           []*Ant{},
+		casteCollected: map[int]int{},
+		food:           spatial.NewGrid[*Food](FOOD_CELL_SIZE, GAME_SIZE, GAME_SIZE),
+		hills:          spatial.NewGrid[vector.Vector](HILL_CELL_SIZE, GAME_SIZE, GAME_SIZE),
+		obstacles:      spatial.NewGrid[*Obstacle](OBSTACLE_CELL_SIZE, GAME_SIZE, GAME_SIZE),
+		walls:          spatial.NewGrid[*wallPiece](WALL_CELL_SIZE, GAME_SIZE, GAME_SIZE),
+		terrain:        NewTerrain(TERRAIN_CELL_SIZE, GAME_SIZE, GAME_SIZE),
+		hazards:        spatial.NewGrid[*Hazard](HAZARD_CELL_SIZE, GAME_SIZE, GAME_SIZE),
+	}
 
-		foragingPheromone:  spatial.NewHash[*Pheromone](PHEROMONE_HASH_CELL_SIZE),
-		returningPheromone: spatial.NewHash[*Pheromone](PHEROMONE_HASH_CELL_SIZE),
+	g.pheromoneTypes = slices.Clone(scenario.pheromoneTypes())
+	for range g.pheromoneTypes {
+		g.pheromones = append(g.pheromones, spatial.NewGrid[*Pheromone](PHEROMONE_CELL_SIZE, GAME_SIZE, GAME_SIZE))
 	}
+
+	g.loadScenario(scenario)
+	return g
 }
 
 func (g *Game) Update() error {
-
 	capture, err := g.ui.Update(ui(g))
 	if err != nil {
 		return fmt.Errorf("error updating ui: %w", err)
@@ -137,11 +192,51 @@ func (g *Game) Update() error {
 	g.uiCapture = capture > 0
 
 	g.pollInput()
+	g.runCommands()
+
+	if g.fastForwardTicks > 0 {
+		return g.updateFastForward()
+	}
+
+	ticks := g.ticksThisFrame()
+	for range ticks {
+		if err := g.tick(); err != nil {
+			return err
+		}
+	}
+
+	if ticks == 0 {
+		g.streamKeyframes()
+	}
 
+	return nil
+}
+
+// tick advances the simulation by one tick.
+func (g *Game) tick() error {
+	start := time.Now()
+
+	if err := g.updateEvents(); err != nil {
+		return err
+	}
+
+	if err := g.updateScript(); err != nil {
+		return err
+	}
+
+	g.updatePredators()
 	g.updateAnts()
 	g.updatePheromones()
 	g.updateFood()
+	g.updateColony()
+
+	if err := g.scoreScript(); err != nil {
+		return err
+	}
 
 	g.tickCount++
+	g.record()
+	g.observeTick(start)
+	g.stream()
 	return nil
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// newTestGame returns a game running scenario, with a hill in the middle if it has none.
+// it starts without ants, set adjusts a copy of DefaultParams before the game is created.
+func newTestGame(scenario Scenario, set func(p *Params)) *Game {
+	params := DefaultParams
+	params.AntCount = 0
+	if set != nil {
+		set(&params)
+	}
+
+	if len(scenario.Hills) == 0 {
+		scenario.Hills = []vector.Vector{{X: 500, Y: 500}}
+	}
+
+	return NewGame(&params, &scenario)
+}
+
+func BenchmarkGameUpdate(b *testing.B) {
+	for _, ants := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("ants=%d", ants), func(b *testing.B) {
+			params := DefaultParams
+			params.AntCount = ants
+			game := NewGame(&params, nil)
+
+			for range BENCH_WARMUP_TICKS {
+				game.Update()
+			}
+
+			for b.Loop() {
+				if err := game.Update(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -5,7 +5,10 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
 	"log"
+	"math"
 	"slices"
 
 	"github.com/rafibayer/ants-again/util"
@@ -21,14 +24,18 @@ const (
 	GYM_SAMPLE_WORKERS = 4 // how many samples per Params run concurrently
 )
 
-func runGym() error {
+// runGym searches for good params forever.
+// if recordPath is set, the stats time series of the best runs median sample is saved there.
+// metrics may be nil, otherwise each sample worker reports as its own sim.
+func runGym(scenario *Scenario, recordPath string, recordEvery int, metrics *Metrics) error {
 	type result struct {
 		iteration int
 		params    Params
-		scores    []int
+		scores    []float64
 		stats     []Stats
-		median    int
+		median    float64
 		medianSt  Stats
+		medianRec *Recorder
 	}
 
 	jobs := make(chan int)
@@ -38,20 +45,33 @@ func runGym() error {
 	for w := 0; w < GYM_PARAM_WORKERS; w++ {
 		go func() {
 			for iteration := range jobs {
-				params := Params{
-					AntSpeed:                       util.Rand(0.5, 2.5),
-					AntRotation:                    util.Rand(0.0, 20.0),
-					AntPheromoneStart:              util.RandInt(5, 120),
-					PheromoneSenseRadius:           util.Rand(GAME_SIZE/50, GAME_SIZE/4),
-					PheromoneSenseCosineSimilarity: util.Rand(-1.0, 1.0),
-					PheromoneDecay:                 float32(util.Rand(1/120.0, 1/1.0)),
-					PheromoneDropProb:              util.Rand(1/180.0, 1/1.0),
-					PheromoneInfluence:             util.Rand(0.1, 10.0),
-					PheromoneSenseProb:             util.Rand(0.05, 1.0),
+				// tune the caste mix along with everything else
+				castes := slices.Clone(ExampleCastes)
+				for i := range castes {
+					castes[i].Ratio = util.Rand(0, 1)
 				}
 
-				scores, stats := runSamples(params)
-				median, medianSt := medianSample(scores, stats)
+				// tuned params take precedence over the scenarios
+				params := *scenario.withParams(&Params{})
+				params.AntCount = ANTS
+				params.AntSpeed = util.Rand(0.5, 2.5)
+				params.AntRotation = util.Rand(0.0, 20.0)
+				params.AntPheromoneStart = util.RandInt(5, 120)
+				params.AntRepellentStart = util.RandInt(0, 20)
+				params.AntAlarmStart = util.RandInt(0, 20)
+				params.PheromoneSenseRadius = util.Rand(GAME_SIZE/50, GAME_SIZE/4)
+				params.PheromoneSenseCosineSimilarity = util.Rand(-1.0, 1.0)
+				params.PheromoneDecay = float32(util.Rand(1/120.0, 1/1.0))
+				params.PheromoneDropProb = util.Rand(1/180.0, 1/1.0)
+				params.PheromoneInfluence = util.Rand(0.1, 10.0)
+				params.PheromoneSenseProb = util.Rand(0.05, 1.0)
+				params.DepositModeIndex = util.RandInt(0, len(depositModes))
+				params.DepositTripReference = util.Rand(1*TPS, 60*TPS)
+				params.PredatorSpeed = DefaultParams.PredatorSpeed
+				params.Castes = castes
+
+				scores, stats, recorders := runSamples(params, scenario, recordPath != "", recordEvery, metrics, w)
+				median, medianIdx := medianSample(scores)
 
 				results <- result{
 					iteration: iteration,
@@ -59,20 +79,22 @@ func runGym() error {
 					scores:    scores,
 					stats:     stats,
 					median:    median,
-					medianSt:  medianSt,
+					medianSt:  stats[medianIdx],
+					medianRec: recorders[medianIdx],
 				}
 			}
 		}()
 	}
 
-	var bestCollected int
+	// custom scores can be negative
+	bestScore := math.Inf(-1)
 	var bestParams Params
 	var bestStats Stats
 
 	defer func() {
 		log.Printf(
-			"best: %d food collected\nparams: %#v\nstats: %#v",
-			bestCollected, bestParams, bestStats,
+			"best score: %g\nparams: %#v\nstats: %#v",
+			bestScore, bestParams, bestStats,
 		)
 	}()
 
@@ -85,37 +107,37 @@ func runGym() error {
 
 	// collect results (single goroutine owns best state)
 	for res := range results {
-		if res.median > bestCollected {
-			bestCollected = res.median
+		if res.median > bestScore {
+			bestScore = res.median
 			bestParams = res.params
 			bestStats = res.medianSt
 
-			minScore := res.scores[0]
-			maxScore := res.scores[0]
-			for _, sc := range res.scores[1:] {
-				if sc < minScore {
-					minScore = sc
-				}
-				if sc > maxScore {
-					maxScore = sc
-				}
-			}
+			minScore := slices.Min(res.scores)
+			maxScore := slices.Max(res.scores)
 
-			log.Printf("[%d] New Best: %d", res.iteration, res.median)
-			log.Printf("[%d] Scores: min=%d median=%d max=%d",
+			log.Printf("[%d] New Best: %g", res.iteration, res.median)
+			log.Printf("[%d] Scores: min=%g median=%g max=%g",
 				res.iteration, minScore, res.median, maxScore)
 			log.Printf("Params: %#v", res.params)
 			log.Printf("Stats (median sample): %#v", res.medianSt)
+
+			if res.medianRec != nil {
+				if err := res.medianRec.Save(recordPath); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
 	return nil
 }
 
-func runSamples(params Params) ([]int, []Stats) {
+// runSamples scores params over GYM_SAMPLES games, by food collected or the scenario scripts custom score.
+func runSamples(params Params, scenario *Scenario, record bool, recordEvery int, metrics *Metrics, paramWorker int) ([]float64, []Stats, []*Recorder) {
 	type sampleResult struct {
-		score int
-		stats Stats
+		score    float64
+		stats    Stats
+		recorder *Recorder
 	}
 
 	work := make(chan struct{})
@@ -125,7 +147,12 @@ func runSamples(params Params) ([]int, []Stats) {
 	for w := 0; w < GYM_SAMPLE_WORKERS; w++ {
 		go func() {
 			for range work {
-				game := NewGame(&params)
+				game := NewGame(&params, scenario)
+				if record {
+					game.recorder = NewRecorder(recordEvery)
+				}
+				game.metrics = metrics
+				game.metricsSim = fmt.Sprintf("gym-%d-%d", paramWorker, w)
 
 				for range GYM_SIM_TIME {
 					if err := game.Update(); err != nil {
@@ -134,9 +161,15 @@ func runSamples(params Params) ([]int, []Stats) {
 				}
 
 				st := game.Stats()
+				score := float64(st.food.collected)
+				if st.scored {
+					score = st.score
+				}
+
 				out <- sampleResult{
-					score: st.food.collected,
-					stats: *st,
+					score:    score,
+					stats:    *st,
+					recorder: game.recorder,
 				}
 			}
 		}()
@@ -150,23 +183,25 @@ func runSamples(params Params) ([]int, []Stats) {
 		close(work)
 	}()
 
-	scores := make([]int, 0, GYM_SAMPLES)
+	scores := make([]float64, 0, GYM_SAMPLES)
 	stats := make([]Stats, 0, GYM_SAMPLES)
+	recorders := make([]*Recorder, 0, GYM_SAMPLES)
 
 	for i := 0; i < GYM_SAMPLES; i++ {
 		r := <-out
 		scores = append(scores, r.score)
 		stats = append(stats, r.stats)
+		recorders = append(recorders, r.recorder)
 	}
 
-	return scores, stats
+	return scores, stats, recorders
 }
 
-// medianSample returns the median score and the Stats belonging to
+// medianSample returns the median score and the index of
 // the actual sample that produced that score.
-func medianSample(scores []int, stats []Stats) (int, Stats) {
+func medianSample(scores []float64) (float64, int) {
 	type pair struct {
-		val int
+		val float64
 		idx int
 	}
 
@@ -178,12 +213,12 @@ func medianSample(scores []int, stats []Stats) (int, Stats) {
 
 	// sort by score only
 	slices.SortFunc(arr, func(a, b pair) int {
-		return a.val - b.val
+		return cmp.Compare(a.val, b.val)
 	})
 
 	mid := n / 2
 	var medianIndex int
-	var medianScore int
+	var medianScore float64
 
 	if n%2 == 1 {
 		medianScore = arr[mid].val
@@ -194,5 +229,5 @@ func medianSample(scores []int, stats []Stats) (int, Stats) {
 		medianIndex = arr[mid].idx
 	}
 
-	return medianScore, stats[medianIndex]
+	return medianScore, medianIndex
 }
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMedianSample(t *testing.T) {
+	median, i := medianSample([]float64{0.75, 0.25, 0.5})
+	require.Equal(t, 0.5, median)
+	require.Equal(t, 2, i)
+
+	// custom scores aren't rounded
+	median, i = medianSample([]float64{0.2, -0.4, 0.6, 0.1})
+	require.InDelta(t, 0.15, median, 1e-9)
+	require.Equal(t, 0, i)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+
+	"github.com/rafibayer/ants-again/spatial"
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+const (
+	HAZARD_CELL_SIZE  = GAME_SIZE / 20.0
+	HAZARD_MAX_RADIUS = GAME_SIZE / 10.0 // hazards are clamped to this radius so they can be found with a radial search
+	HAZARD_RADIUS     = GAME_SIZE / 40.0 // radius of hazards placed with the cursor
+
+	PREDATOR_SIGHT_RADIUS = GAME_SIZE / 8.0  // predators chase the nearest ant within this radius
+	PREDATOR_SCARE_RADIUS = GAME_SIZE / 20.0 // ants within this radius of a predator are alarmed
+	PREDATOR_KILL_RADIUS  = GAME_SIZE / 200.0
+	PREDATOR_TURN         = 0.2 // how quickly predators turn towards their prey, 1.0 is instantly
+	PREDATOR_WANDER       = 15  // random rotation in degrees per tick when no prey is in sight
+)
+
+// Hazard is a static circular zone. lethal hazards kill ants that enter them,
+// others scare the ant away and trigger an alarm.
+type Hazard struct {
+	vector.Vector
+
+	radius float64
+	lethal bool
+}
+
+// Predator is a mobile hazard that chases and kills ants.
+type Predator struct {
+	vector.Vector
+
+	dir vector.Vector
+}
+
+func newHazard(pos vector.Vector, radius float64, lethal bool) *Hazard {
+	return &Hazard{Vector: pos, radius: min(radius, HAZARD_MAX_RADIUS), lethal: lethal}
+}
+
+func newPredator(pos vector.Vector) *Predator {
+	return &Predator{Vector: pos, dir: vector.Vector{X: util.Rand(-1, 1), Y: util.Rand(-1, 1)}.Normalize()}
+}
+
+func (g *Game) addHazard(pos vector.Vector, radius float64, lethal bool) *Hazard {
+	h := newHazard(pos, radius, lethal)
+	g.hazards.Insert(h)
+	return h
+}
+
+func (g *Game) addPredator(pos vector.Vector) *Predator {
+	p := newPredator(pos)
+	g.predators = append(g.predators, p)
+	return p
+}
+
+// checkHazards kills or scares an ant standing in a hazard zone.
+func (g *Game) checkHazards(ant *Ant) {
+	for h := range g.hazards.RadialSearchIter(ant.Vector, HAZARD_MAX_RADIUS) {
+		if ant.Distance(h.Vector) > h.radius {
+			continue
+		}
+
+		if h.lethal {
+			g.killAnt(ant)
+			return
+		}
+
+		g.alarm(ant, h.Vector)
+	}
+}
+
+func (g *Game) killAnt(ant *Ant) {
+	if !ant.dead {
+		ant.dead = true
+		g.killedAntCount++
+	}
+}
+
+// alarm turns the ant away from a threat, and has it mark the area with alarm pheromone.
+func (g *Game) alarm(ant *Ant, threat vector.Vector) {
+	away := ant.Sub(threat)
+	if away.Magnitude() > 0 {
+		ant.dir = away.Normalize()
+	}
+
+	if ant.alarmStored == 0 {
+		ant.alarmStored = g.params.AntAlarmStart
+	}
+}
+
+// updatePredators moves each predator towards the nearest ant in sight,
+// killing ants it catches and alarming those nearby.
+func (g *Game) updatePredators() {
+	if len(g.predators) == 0 {
+		return
+	}
+
+	// index the ants so predators can find them
+	ants := spatial.NewGrid[*Ant](PHEROMONE_CELL_SIZE, GAME_SIZE, GAME_SIZE)
+	for _, ant := range g.ants {
+		ants.Insert(ant)
+	}
+
+	for _, p := range g.predators {
+		var prey *Ant
+		nearest := math.Inf(1)
+		for ant := range ants.RadialSearchIter(p.Vector, PREDATOR_SIGHT_RADIUS) {
+			if d := p.Distance2(ant.Vector); !ant.dead && d < nearest {
+				prey, nearest = ant, d
+			}
+		}
+
+		if prey != nil {
+			toPrey := prey.Sub(p.Vector)
+			if toPrey.Magnitude() > 0 {
+				p.dir = p.dir.Add(toPrey.Normalize().Mul(PREDATOR_TURN)).Normalize()
+			}
+		} else {
+			p.dir = p.dir.Rotate(util.Rand(-PREDATOR_WANDER, PREDATOR_WANDER))
+		}
+
+		step := p.dir.Mul(g.params.PredatorSpeed)
+		if normal, hit := g.collideWalls(p.Vector, p.Add(step)); hit {
+			p.dir = p.dir.Sub(normal.Mul(2 * p.dir.Dot(normal)))
+		} else {
+			p.Vector = p.Add(step)
+		}
+
+		// predators always turn around at the world boundary
+		if p.X < 0 || p.X >= GAME_SIZE {
+			p.dir.X *= -1
+			p.X = util.Clamp(0, p.X, GAME_SIZE-1)
+		}
+		if p.Y < 0 || p.Y >= GAME_SIZE {
+			p.dir.Y *= -1
+			p.Y = util.Clamp(0, p.Y, GAME_SIZE-1)
+		}
+
+		for ant := range ants.RadialSearchIter(p.Vector, PREDATOR_SCARE_RADIUS) {
+			if p.Distance(ant.Vector) <= PREDATOR_KILL_RADIUS {
+				g.killAnt(ant)
+			} else {
+				g.alarm(ant, p.Vector)
+			}
+		}
+	}
+}
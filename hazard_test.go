@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLethalHazard(t *testing.T) {
+	game := newTestGame(Scenario{
+		Hazards: []HazardSpec{{X: 500, Y: 500, Radius: 50, Lethal: true}},
+	}, func(p *Params) {
+		p.AntCount = 10
+	})
+
+	game.Update()
+
+	require.Empty(t, game.ants)
+	require.Equal(t, 10, game.deadAntCount)
+	require.Equal(t, 10, game.killedAntCount)
+}
+
+func TestLethalHazardOnFood(t *testing.T) {
+	game := newTestGame(Scenario{
+		Hazards: []HazardSpec{{X: 100, Y: 100, Radius: 20, Lethal: true}},
+	}, nil)
+
+	food := &Food{amount: 1, capacity: 1, quality: 1, Vector: &vector.Vector{X: 100, Y: 100}}
+	game.food.Insert(food)
+	game.spawnAnt(vector.Vector{X: 100, Y: 100})
+
+	game.Update()
+
+	// dead ants don't forage
+	require.Equal(t, 1, game.killedAntCount)
+	require.Equal(t, 1, food.amount)
+	require.Zero(t, game.pickedFoodCount)
+	require.Zero(t, game.foragingAntCount)
+}
+
+func TestHazardAlarm(t *testing.T) {
+	game := newTestGame(Scenario{
+		Hazards: []HazardSpec{{X: 100, Y: 100, Radius: 20}},
+	}, func(p *Params) {
+		p.AntAlarmStart = 5
+	})
+
+	ant := game.spawnAnt(vector.Vector{X: 110, Y: 100})
+	ant.dir = vector.Vector{X: -1, Y: 0}
+	game.checkHazards(ant)
+
+	// turned away and ready to warn the others
+	require.False(t, ant.dead)
+	require.Greater(t, ant.dir.X, 0.0)
+	require.Equal(t, game.params.AntAlarmStart, ant.alarmStored)
+}
+
+func TestPredatorKills(t *testing.T) {
+	game := newTestGame(Scenario{
+		Predators: []vector.Vector{{X: 100, Y: 100}},
+	}, func(p *Params) {
+		p.AntAlarmStart = 5
+	})
+
+	prey := game.spawnAnt(vector.Vector{X: 100, Y: 100})
+	nearby := game.spawnAnt(vector.Vector{X: 100 + PREDATOR_SCARE_RADIUS/2, Y: 100})
+
+	game.updatePredators()
+
+	require.True(t, prey.dead)
+	require.False(t, nearby.dead)
+	require.Equal(t, game.params.AntAlarmStart, nearby.alarmStored)
+}
+
+func TestScenarioHazardRadius(t *testing.T) {
+	scenario := &Scenario{
+		Hills:   []vector.Vector{{X: 500, Y: 500}},
+		Hazards: []HazardSpec{{X: 100, Y: 100, Radius: HAZARD_MAX_RADIUS * 2}},
+	}
+	require.Error(t, scenario.validate())
+}
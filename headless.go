@@ -0,0 +1,44 @@
+// headless runs of the simulation, for collecting stats without a window.
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const HEADLESS_TICKS = 10 * 60 * TPS
+
+// runHeadless runs the simulation without rendering for the given number of ticks.
+// attach sets up recording, metrics, control and streaming for the game before it starts,
+// pausing it through the control API pauses the run.
+func runHeadless(scenario *Scenario, ticks int, attach func(*Game) error) error {
+	game := NewGame(nil, scenario)
+	if err := attach(game); err != nil {
+		return err
+	}
+
+	// speed and fast-forward from the control API run several ticks per update, don't overshoot
+	game.tickLimit = ticks
+
+	start := time.Now()
+	for game.tickCount < ticks {
+		if err := game.Update(); err != nil {
+			return err
+		}
+
+		// don't spin while waiting for the control API
+		if game.paused && game.stepTicks == 0 && game.fastForwardTicks == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	stats := game.Stats()
+	fmt.Printf("ticks=%d elapsed=%s collected=%d ants=%d\n",
+		ticks, time.Since(start).Round(time.Millisecond), stats.food.collected, stats.ants.alive)
+
+	if game.recorder == nil {
+		return nil
+	}
+
+	return game.recorder.Save(game.recordPath)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/rafibayer/ants-again/spatial"
+)
+
+const HISTORY_LIMIT = 100 // strokes kept for undo
+
+// edit is a reversible change to the world.
+type edit struct {
+	undo, redo func()
+}
+
+// History records the edits made with the mouse so they can be undone and redone.
+// edits are grouped into strokes, everything from a mouse press until its release is undone at once.
+type History struct {
+	undos  [][]edit
+	redos  [][]edit
+	stroke []edit
+	open   bool // whether edits are added to the stroke, or are a stroke of their own
+}
+
+// begin starts a new stroke.
+func (h *History) begin() {
+	h.end()
+	h.open = true
+}
+
+// end finishes the current stroke, if any.
+func (h *History) end() {
+	h.open = false
+	if len(h.stroke) == 0 {
+		return
+	}
+
+	h.undos = append(h.undos, h.stroke)
+	if len(h.undos) > HISTORY_LIMIT {
+		h.undos = slices.Delete(h.undos, 0, len(h.undos)-HISTORY_LIMIT)
+	}
+	h.stroke = nil
+}
+
+// record adds an edit that has already been applied, new edits can't be redone past.
+func (h *History) record(e edit) {
+	h.stroke = append(h.stroke, e)
+	h.redos = nil
+
+	if !h.open {
+		h.end()
+	}
+}
+
+// undo reverts the last stroke, in reverse order. it returns false if there's nothing to undo.
+func (h *History) undo() bool {
+	h.end()
+	if len(h.undos) == 0 {
+		return false
+	}
+
+	stroke := h.undos[len(h.undos)-1]
+	h.undos = h.undos[:len(h.undos)-1]
+	for i := len(stroke) - 1; i >= 0; i-- {
+		stroke[i].undo()
+	}
+
+	h.redos = append(h.redos, stroke)
+	return true
+}
+
+// redo reapplies the last undone stroke. it returns false if there's nothing to redo.
+func (h *History) redo() bool {
+	h.end()
+	if len(h.redos) == 0 {
+		return false
+	}
+
+	stroke := h.redos[len(h.redos)-1]
+	h.redos = h.redos[:len(h.redos)-1]
+	for _, e := range stroke {
+		e.redo()
+	}
+
+	h.undos = append(h.undos, stroke)
+	return true
+}
+
+// edit applies a change to the world and records it in the history.
+func (g *Game) edit(do, undo func()) {
+	do()
+	g.history.record(edit{undo: undo, redo: do})
+}
+
+// pollHistoryKeys handles ctrl-z (undo), and ctrl-y or ctrl-shift-z (redo). cmd works too on macs.
+func (g *Game) pollHistoryKeys() {
+	if !ebiten.IsKeyPressed(ebiten.KeyControl) && !ebiten.IsKeyPressed(ebiten.KeyMeta) {
+		return
+	}
+
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyZ) && !shift:
+		g.history.undo()
+	case inpututil.IsKeyJustPressed(ebiten.KeyY), inpututil.IsKeyJustPressed(ebiten.KeyZ) && shift:
+		g.history.redo()
+	}
+}
+
+func (g *Game) editAddFood(f *Food) {
+	g.edit(func() { g.food.Insert(f) }, func() { spatial.RemoveSame(g.food, f) })
+}
+
+func (g *Game) editRemoveFood(f *Food) {
+	g.edit(func() { spatial.RemoveSame(g.food, f) }, func() { g.food.Insert(f) })
+}
+
+func (g *Game) editAddObstacle(o *Obstacle) {
+	g.edit(func() { g.obstacles.Insert(o) }, func() { spatial.RemoveSame(g.obstacles, o) })
+}
+
+func (g *Game) editRemoveObstacle(o *Obstacle) {
+	g.edit(func() { spatial.RemoveSame(g.obstacles, o) }, func() { g.obstacles.Insert(o) })
+}
+
+func (g *Game) editAddWall(w *Wall) {
+	g.edit(func() { g.insertWall(w) }, func() { g.deleteWall(w) })
+}
+
+func (g *Game) editAddPolygon(p *Polygon) {
+	g.edit(func() { g.insertPolygon(p) }, func() { g.removePolygon(p) })
+}
+
+// editRemoveWall removes a wall, or its whole polygon if it has one, see removeWall.
+func (g *Game) editRemoveWall(w *Wall) {
+	if p := w.polygon; p != nil {
+		// several edges of the same polygon can be removed at once
+		if slices.Contains(g.polygons, p) {
+			g.edit(func() { g.removePolygon(p) }, func() { g.insertPolygon(p) })
+		}
+		return
+	}
+
+	g.edit(func() { g.deleteWall(w) }, func() { g.insertWall(w) })
+}
+
+func (g *Game) editAddHazard(h *Hazard) {
+	g.edit(func() { g.hazards.Insert(h) }, func() { spatial.RemoveSame(g.hazards, h) })
+}
+
+func (g *Game) editRemoveHazard(h *Hazard) {
+	g.edit(func() { spatial.RemoveSame(g.hazards, h) }, func() { g.hazards.Insert(h) })
+}
+
+func (g *Game) editAddPredator(p *Predator) {
+	remove := func() {
+		g.predators = slices.DeleteFunc(g.predators, func(other *Predator) bool { return other == p })
+	}
+	g.edit(func() { g.predators = append(g.predators, p) }, remove)
+}
+
+func (g *Game) editRemovePredator(p *Predator) {
+	remove := func() {
+		g.predators = slices.DeleteFunc(g.predators, func(other *Predator) bool { return other == p })
+	}
+	g.edit(remove, func() { g.predators = append(g.predators, p) })
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryStrokes(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	// one stroke of 3 obstacles, then a wall on its own
+	game.history.begin()
+	for i := range 3 {
+		game.editAddObstacle(&Obstacle{Vector: vector.Vector{X: float64(100 + i), Y: 100}})
+	}
+	game.history.end()
+	game.editAddWall(&Wall{A: vector.Vector{X: 0, Y: 0}, B: vector.Vector{X: 100, Y: 0}})
+
+	require.Equal(t, 3, game.obstacles.Len())
+	require.NotEmpty(t, game.wallsNear(vector.Vector{X: 50, Y: 0}, 1))
+
+	require.True(t, game.history.undo())
+	require.Empty(t, game.wallsNear(vector.Vector{X: 50, Y: 0}, 1))
+	require.Equal(t, 3, game.obstacles.Len())
+
+	require.True(t, game.history.undo())
+	require.Equal(t, 0, game.obstacles.Len())
+	require.False(t, game.history.undo())
+
+	require.True(t, game.history.redo())
+	require.Equal(t, 3, game.obstacles.Len())
+
+	// a new edit clears what could be redone
+	game.editRemoveObstacle(game.obstacles.RadialSearch(vector.Vector{X: 100, Y: 100}, 0.5)[0])
+	require.Equal(t, 2, game.obstacles.Len())
+	require.False(t, game.history.redo())
+
+	require.True(t, game.history.undo())
+	require.Equal(t, 3, game.obstacles.Len())
+}
+
+func TestHistorySharedPosition(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	// food from the scenario, and food added on top of it
+	p := vector.Vector{X: 100, Y: 100}
+	old := &Food{amount: 1, capacity: 1, quality: 1, Vector: &vector.Vector{X: p.X, Y: p.Y}}
+	game.food.Insert(old)
+	added := &Food{amount: 2, capacity: 2, quality: 1, Vector: &vector.Vector{X: p.X, Y: p.Y}}
+	game.editAddFood(added)
+
+	// undo removes the food it added, not whichever was first at the spot
+	require.True(t, game.history.undo())
+	require.Equal(t, 1, game.food.Len())
+	require.Same(t, old, game.food.Points()[0])
+
+	require.True(t, game.history.redo())
+	game.editRemoveFood(old)
+	require.True(t, game.history.undo())
+	require.True(t, game.history.undo())
+	require.Equal(t, 1, game.food.Len())
+	require.Same(t, old, game.food.Points()[0])
+
+	// same for obstacles and hazards
+	oldObs := &Obstacle{Vector: p}
+	game.obstacles.Insert(oldObs)
+	game.editAddObstacle(&Obstacle{Vector: p})
+	require.True(t, game.history.undo())
+	require.Equal(t, 1, game.obstacles.Len())
+	require.Same(t, oldObs, game.obstacles.Points()[0])
+
+	oldHazard := newHazard(p, 10, false)
+	game.hazards.Insert(oldHazard)
+	game.editAddHazard(newHazard(p, 20, true))
+	require.True(t, game.history.undo())
+	require.Equal(t, 1, game.hazards.Len())
+	require.Same(t, oldHazard, game.hazards.Points()[0])
+}
+
+func TestHistoryOverlappingWalls(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	// two walls along the same line, so their pieces share midpoints
+	first := &Wall{A: vector.Vector{X: 0, Y: 100}, B: vector.Vector{X: 100, Y: 100}}
+	game.editAddWall(first)
+	second := &Wall{A: vector.Vector{X: 0, Y: 100}, B: vector.Vector{X: 100, Y: 100}}
+	game.editAddWall(second)
+
+	require.True(t, game.history.undo())
+	require.Equal(t, len(first.pieces), game.walls.Len())
+	for piece := range game.walls.PointsIter() {
+		require.Same(t, first, piece.wall)
+	}
+}
+
+func TestHistoryRemovePolygon(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+	game.addPolygon([]vector.Vector{{X: 100, Y: 100}, {X: 200, Y: 100}, {X: 200, Y: 200}})
+
+	// erasing near a corner touches two edges of the same polygon
+	game.history.begin()
+	walls := game.wallsNear(vector.Vector{X: 200, Y: 100}, 1)
+	require.Len(t, walls, 2)
+	for _, w := range walls {
+		game.editRemoveWall(w)
+	}
+	game.history.end()
+	require.Empty(t, game.polygons)
+
+	require.True(t, game.history.undo())
+	require.Len(t, game.polygons, 1)
+	require.Len(t, game.wallsNear(vector.Vector{X: 200, Y: 100}, 1), 2)
+}
+
+func TestHistoryLimit(t *testing.T) {
+	var h History
+	undone := 0
+	for range HISTORY_LIMIT + 10 {
+		h.record(edit{undo: func() { undone++ }, redo: func() {}})
+	}
+
+	for h.undo() {
+	}
+	require.Equal(t, HISTORY_LIMIT, undone)
+}
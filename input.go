@@ -1,19 +1,29 @@
 package main
 
 import (
+	"slices"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/rafibayer/ants-again/vector"
 )
 
 type CursorMode int
 
 const (
-	CursorModeNone CursorMode = iota
-	CursorModeFood
-	CursorModeObstacle
+	CursorModeNone     CursorMode = iota
+	CursorModeFood                // paint with the brush shape, see brushShapes
+	CursorModeObstacle            // paint with the brush shape
+	CursorModeWall                // drag to draw a wall
+	CursorModePolygon             // click to add vertices, right click to close
+	CursorModeHazard              // click to place a lethal hazard, shift click for a non-lethal one
+	CursorModePredator            // click to place a predator
+	CursorModeHill                // click to place a hill
+	CursorModeAnts                // click to spawn ants within the brush radius
+	CursorModeInspect             // click to select an ant, see inspect.go
 )
 
-var cursorOptions = []string{"None", "Food", "Obstacle"}
+var cursorOptions = []string{"None", "Food", "Obstacle", "Wall", "Polygon", "Hazard", "Predator", "Hill", "Ants", "Inspect"}
 
 func (g *Game) pollInput() {
 	// Camera movement
@@ -38,39 +48,140 @@ func (g *Game) pollInput() {
 		g.zoom *= 0.98
 	}
 
+	g.pollPlaybackKeys()
+	g.pollHistoryKeys()
+	g.updateSelection()
+
+	// a stroke ends once every button is released, even over the UI
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && !ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		g.history.end()
+	}
+
 	// ignore game mouse inputs if captured by UI
 	if g.uiCapture {
 		return
 	}
 
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.history.begin()
+	}
+
 	xs, ys := ebiten.CursorPosition()
 	xw, yw := g.screenToWorldSpace(float64(xs), float64(ys))
 	v := vector.Vector{X: xw, Y: yw}
+	g.cursor = v
 
 	cursorMode := CursorMode(g.cursorModeIndex)
 
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		switch cursorMode {
-		case CursorModeFood:
-			g.food.Insert(&Food{amount: FOOD_START, Vector: &v})
-		case CursorModeObstacle:
-			g.obstacles.Insert(&Obstacle{Vector: v})
-		default:
+	// drafts are abandoned when switching modes
+	if cursorMode != CursorModeWall {
+		g.wallStart = nil
+	}
+	if cursorMode != CursorModePolygon {
+		g.polygonDraft = nil
+	}
+	shape := BrushShape(g.brushShapeIndex)
+	if (cursorMode != CursorModeFood && cursorMode != CursorModeObstacle) || shape == BrushFreehand {
+		g.shapeStart = nil
+	}
+
+	switch cursorMode {
+	case CursorModeFood, CursorModeObstacle:
+		if shape == BrushFreehand {
+			// freehand paints every frame the button is held
+			if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+				g.paint(cursorMode, shape, v, v)
+			}
+			break
+		}
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.shapeStart = &v
+		}
+		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) && g.shapeStart != nil {
+			g.paint(cursorMode, shape, *g.shapeStart, v)
+			g.shapeStart = nil
 		}
+	case CursorModeWall:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.wallStart = &v
+		}
+		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) && g.wallStart != nil {
+			if g.wallStart.Distance(v) > 0 {
+				g.editAddWall(&Wall{A: *g.wallStart, B: v})
+			}
+			g.wallStart = nil
+		}
+	case CursorModePolygon:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.polygonDraft = append(g.polygonDraft, v)
+		}
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+			if len(g.polygonDraft) >= 3 {
+				g.editAddPolygon(&Polygon{Vertices: g.polygonDraft})
+			}
+			g.polygonDraft = nil
+		}
+	case CursorModeHazard:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.editAddHazard(newHazard(v, HAZARD_RADIUS, !ebiten.IsKeyPressed(ebiten.KeyShift)))
+		}
+	case CursorModePredator:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.editAddPredator(newPredator(v))
+		}
+	case CursorModeHill:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.editAddHill(v)
+		}
+	case CursorModeAnts:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.spawnAnts(v, g.brushAntCount)
+		}
+	case CursorModeInspect:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.selectAnt(v)
+		}
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+			g.selected = nil
+		}
+	default:
 	}
 
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
 		switch cursorMode {
 		case CursorModeFood:
-			toRemove := g.food.RadialSearch(v, ANT_FOOD_RADIUS)
+			toRemove := g.food.RadialSearch(v, max(g.brushRadius, ANT_FOOD_RADIUS))
 			for _, r := range toRemove {
-				g.food.Remove(r)
+				g.editRemoveFood(r)
 			}
 		case CursorModeObstacle:
-			toRemove := g.obstacles.RadialSearch(v, OBSTACLE_HASH_CELL_SIZE)
+			toRemove := g.obstacles.RadialSearch(v, max(g.brushRadius, OBSTACLE_CELL_SIZE))
 			for _, r := range toRemove {
-				g.obstacles.Remove(r)
+				g.editRemoveObstacle(r)
+			}
+		case CursorModeWall:
+			// removing any edge of a polygon removes the whole polygon
+			for _, w := range g.wallsNear(v, OBSTACLE_CELL_SIZE) {
+				g.editRemoveWall(w)
+			}
+		case CursorModeHazard:
+			for _, h := range g.hazards.RadialSearch(v, HAZARD_MAX_RADIUS) {
+				if h.Distance(v) <= h.radius {
+					g.editRemoveHazard(h)
+				}
+			}
+		case CursorModePredator:
+			for _, p := range slices.Clone(g.predators) {
+				if p.Distance(v) <= PREDATOR_SCARE_RADIUS {
+					g.editRemovePredator(p)
+				}
+			}
+		case CursorModeHill:
+			for _, hill := range g.hills.RadialSearch(v, ANT_HILL_RADIUS) {
+				g.editRemoveHill(hill)
 			}
+		case CursorModeAnts:
+			g.editRemoveAnts(g.antsNear(v, max(g.brushRadius, OBSTACLE_CELL_SIZE)))
 		default:
 		}
 	}
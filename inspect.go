@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	vec "github.com/rafibayer/ants-again/vector"
+)
+
+const INSPECT_RADIUS = GAME_SIZE / 100.0 // how close a click has to be to select an ant
+
+// selectAnt selects the nearest living ant within INSPECT_RADIUS of v, or clears the selection if there are none.
+func (g *Game) selectAnt(v vec.Vector) {
+	g.selected = nil
+
+	nearest := INSPECT_RADIUS
+	for _, ant := range g.ants {
+		if d := ant.Distance(v); !ant.dead && d <= nearest {
+			nearest = d
+			g.selected = ant
+		}
+	}
+}
+
+// updateSelection drops the selection once the ant is gone, and centers the camera on it while following.
+func (g *Game) updateSelection() {
+	if g.selected == nil {
+		return
+	}
+
+	if g.selected.dead || !slices.Contains(g.ants, g.selected) {
+		g.selected = nil
+		return
+	}
+
+	if g.followSelected {
+		g.camX = g.selected.X - screenW/2
+		g.camY = g.selected.Y - screenH/2
+	}
+}
+
+// inspect describes the ant, one line per field, for the inspector window.
+func (g *Game) inspect(ant *Ant) []string {
+	state := "forage"
+	if ant.state == RETURN {
+		state = "return"
+	}
+
+	sensed := g.sensePheromones(ant, g.senseRadius(ant))
+
+	return []string{
+		fmt.Sprintf("id: %d (%s)", ant.id, g.casteOf(ant).Name),
+		fmt.Sprintf("state: %s", state),
+		fmt.Sprintf("position: %.1f, %.1f", ant.X, ant.Y),
+		fmt.Sprintf("heading: %.1f deg", heading(ant.dir)),
+		fmt.Sprintf("pheromone stored: %d", ant.pheromoneStored),
+		fmt.Sprintf("repellent / alarm stored: %d / %d", ant.repellentStored, ant.alarmStored),
+		fmt.Sprintf("carrying: %.2f", ant.carrying),
+		fmt.Sprintf("trip ticks: %d (last %d)", ant.tripTicks, ant.lastTrip),
+		fmt.Sprintf("sensed: %.2f at %.1f deg", sensed.Magnitude(), heading(sensed)),
+		fmt.Sprintf("home: %.1f, %.1f", ant.home.X, ant.home.Y),
+		fmt.Sprintf("energy: %.0f, age: %d", max(0, g.params.AntEnergy-ant.walked), ant.age),
+	}
+}
+
+// heading is the angle of v in degrees, clockwise from the x axis in world space.
+func heading(v vec.Vector) float64 {
+	return math.Atan2(v.Y, v.X) * 180 / math.Pi
+}
+
+// drawSelection highlights the selected ant, its sense cone and the pheromone pull it senses.
+func (g *Game) drawSelection() {
+	ant := g.selected
+	if ant == nil {
+		return
+	}
+
+	x, y := float32(ant.X), float32(ant.Y)
+	radius := g.senseRadius(ant)
+
+	// pheromones outside the cone are ignored, see sensePheromones
+	half := math.Acos(max(-1, min(1, g.params.PheromoneSenseCosineSimilarity)))
+	angle := math.Atan2(ant.dir.Y, ant.dir.X)
+
+	var cone vector.Path
+	cone.MoveTo(x, y)
+	cone.Arc(x, y, float32(radius), float32(angle-half), float32(angle+half), vector.Clockwise)
+	cone.Close()
+
+	op := &vector.DrawPathOptions{AntiAlias: true}
+	op.ColorScale.ScaleWithColor(YELLOW)
+	vector.StrokePath(g.world, &cone, &vector.StrokeOptions{Width: 2}, op)
+
+	vector.StrokeCircle(g.world, x, y, 6, 2, YELLOW, true)
+
+	sensed := g.sensePheromones(ant, radius)
+	if sensed.Magnitude() > 0 {
+		tip := ant.Add(sensed.Normalize().Mul(radius / 2))
+		vector.StrokeLine(g.world, x, y, float32(tip.X), float32(tip.Y), 2, WHITE, true)
+	}
+}
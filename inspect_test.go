@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectAnt(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	near := game.spawnAnt(vector.Vector{X: 100, Y: 100})
+	far := game.spawnAnt(vector.Vector{X: 105, Y: 100})
+
+	game.selectAnt(vector.Vector{X: 101, Y: 100})
+	require.Same(t, near, game.selected)
+
+	game.selectAnt(vector.Vector{X: 104, Y: 100})
+	require.Same(t, far, game.selected)
+
+	// clicking empty space deselects
+	game.selectAnt(vector.Vector{X: 300, Y: 300})
+	require.Nil(t, game.selected)
+
+	game.selectAnt(vector.Vector{X: 101, Y: 100})
+	lines := game.inspect(game.selected)
+	require.Contains(t, lines, "state: forage")
+	require.Contains(t, lines, "pheromone stored: 10")
+
+	// the camera centers on the ant
+	game.followSelected = true
+	game.updateSelection()
+	require.Equal(t, near.X-screenW/2, game.camX)
+	require.Equal(t, near.Y-screenH/2, game.camY)
+
+	// the selection is dropped with the ant
+	game.killAnt(near)
+	game.updateSelection()
+	require.Nil(t, game.selected)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -13,9 +14,68 @@ import (
 func main() {
 	var gym bool
 	var cpu bool
+	var scenarioPath string
+	var recordPath string
+	var recordEvery int
+	var metricsAddr string
+	var controlAddr string
+	var streamAddr string
+	var streamEvery int
+
+	// startMetrics serves metrics if --metrics-addr is set, otherwise returns nil.
+	startMetrics := func() (*Metrics, error) {
+		if metricsAddr == "" {
+			return nil, nil
+		}
+
+		metrics := NewMetrics()
+		return metrics, serveMetrics(metricsAddr, metrics)
+	}
+
+	// attach sets up a game for the --record, --control-addr and --stream-addr flags.
+	attach := func(game *Game, metrics *Metrics) error {
+		if recordPath != "" {
+			game.recorder = NewRecorder(recordEvery)
+			game.recordPath = recordPath
+		}
+
+		game.metrics = metrics
+		game.metricsSim = "main"
+
+		if controlAddr != "" {
+			if err := serveControl(controlAddr, game); err != nil {
+				return err
+			}
+		}
+
+		if streamAddr != "" {
+			game.streamer = NewStreamer(streamEvery)
+			if err := serveStream(streamAddr, game.streamer); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// loadScenario returns the scenario from the --scenario flag, or the pages url in the browser, or nil for the default.
+	loadScenario := func() (*Scenario, error) {
+		if scenarioPath == "" {
+			return browserScenario()
+		}
+
+		return LoadScenario(scenarioPath)
+	}
 
 	rootCmd := &cobra.Command{
 		Use: "ants-again",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if recordPath != "" {
+				return checkRecordPath(recordPath)
+			}
+
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ebiten.SetTPS(TPS)
 
@@ -32,24 +92,134 @@ func main() {
 				}()
 			}
 
+			scenario, err := loadScenario()
+			if err != nil {
+				return err
+			}
+
+			metrics, err := startMetrics()
+			if err != nil {
+				return err
+			}
+
 			if gym {
-				return runGym()
+				return runGym(scenario, recordPath, recordEvery, metrics)
 			}
 
 			var params *Params
-			game := NewGame(params)
+			game := NewGame(params, scenario)
+			if err := attach(game, metrics); err != nil {
+				return err
+			}
+
 			ebiten.SetWindowSize(800, 800)
 			ebiten.SetWindowTitle("Hello, World!")
 			if err := ebiten.RunGame(game); err != nil {
 				log.Fatal(err)
 			}
 
+			if game.recorder != nil {
+				return game.recorder.Save(recordPath)
+			}
+
 			return nil
 		},
 	}
 
 	rootCmd.Flags().BoolVar(&gym, "gym", false, "Enable gym mode")
 	rootCmd.Flags().BoolVar(&cpu, "cpu", false, "Enable CPU mode")
+	rootCmd.PersistentFlags().StringVar(&scenarioPath, "scenario", "", "Path to a scenario JSON file")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "Record a stats time series to this .csv or .json file")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9090")
+	rootCmd.PersistentFlags().StringVar(&controlAddr, "control-addr", "", "Serve the HTTP/JSON control API on this address, e.g. :8080")
+	rootCmd.PersistentFlags().IntVar(&recordEvery, "record-every", RECORD_EVERY, "Ticks between recorded stats samples")
+	rootCmd.PersistentFlags().StringVar(&streamAddr, "stream-addr", "", "Stream the world to browser viewers on this address, e.g. :8081")
+	rootCmd.PersistentFlags().IntVar(&streamEvery, "stream-every", STREAM_EVERY, "Ticks between streamed frames")
+
+	var benchAnts []int
+	var benchTicks int
+
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Run the simulation headless and print ticks/sec",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scenario, err := loadScenario()
+			if err != nil {
+				return err
+			}
+
+			return runBench(scenario, benchAnts, benchTicks)
+		},
+	}
+
+	benchCmd.Flags().IntSliceVar(&benchAnts, "ants", []int{ANTS}, "Ant counts to benchmark")
+	benchCmd.Flags().IntVar(&benchTicks, "ticks", BENCH_TICKS, "Ticks to run per ant count")
+	rootCmd.AddCommand(benchCmd)
+
+	var runTicks int
+
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the simulation headless, see --record",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scenario, err := loadScenario()
+			if err != nil {
+				return err
+			}
+
+			metrics, err := startMetrics()
+			if err != nil {
+				return err
+			}
+
+			return runHeadless(scenario, runTicks, func(game *Game) error {
+				return attach(game, metrics)
+			})
+		},
+	}
+
+	runCmd.Flags().IntVar(&runTicks, "ticks", HEADLESS_TICKS, "Ticks to simulate")
+	rootCmd.AddCommand(runCmd)
+
+	var envListen string
+	var envConfigPath string
+
+	envCmd := &cobra.Command{
+		Use:   "env",
+		Short: "Serve a reinforcement learning environment over stdio, or TCP with --listen",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scenario, err := loadScenario()
+			if err != nil {
+				return err
+			}
+
+			var config EnvConfig
+			if envConfigPath != "" {
+				data, err := os.ReadFile(envConfigPath)
+				if err != nil {
+					return fmt.Errorf("error reading env config: %w", err)
+				}
+				if err := json.Unmarshal(data, &config); err != nil {
+					return fmt.Errorf("error parsing env config: %w", err)
+				}
+			}
+
+			env, err := NewEnv(config, scenario)
+			if err != nil {
+				return err
+			}
+
+			if envListen != "" {
+				return listenEnv(env, envListen)
+			}
+
+			return serveEnv(env, os.Stdin, os.Stdout)
+		},
+	}
+
+	envCmd.Flags().StringVar(&envListen, "listen", "", "Serve on this TCP address instead of stdio, e.g. localhost:5555")
+	envCmd.Flags().StringVar(&envConfigPath, "config", "", "Path to an EnvConfig JSON file")
+	rootCmd.AddCommand(envCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tick duration histogram buckets, in seconds. a tick has 1/TPS seconds to keep up in real time.
+var tickBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 1.0 / TPS, 0.025, 0.05, 0.1, 0.25, 1}
+
+// Metrics collects the stats of running games for a Prometheus scrape.
+// games report to it every tick from their own goroutine, scrapes read a snapshot.
+type Metrics struct {
+	mu sync.Mutex
+
+	sims map[string]*simMetrics // by sim label, "main" unless running the gym
+
+	tickCounts []uint64 // per tickBuckets entry, not cumulative. the last is +Inf
+	tickSum    float64
+	tickCount  uint64
+}
+
+type simMetrics struct {
+	stats *Stats
+
+	// ticks per second measured over the last second, ebiten.ActualTPS is 0 when headless
+	tps         float64
+	windowStart time.Time
+	windowTicks int
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		sims:       map[string]*simMetrics{},
+		tickCounts: make([]uint64, len(tickBuckets)+1),
+	}
+}
+
+// observe records a finished tick of the game.
+func (m *Metrics) observe(sim string, stats *Stats, tick time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seconds := tick.Seconds()
+	bucket, _ := slices.BinarySearch(tickBuckets, seconds)
+	m.tickCounts[bucket]++
+	m.tickSum += seconds
+	m.tickCount++
+
+	s, ok := m.sims[sim]
+	if !ok {
+		s = &simMetrics{windowStart: time.Now()}
+		m.sims[sim] = s
+	}
+
+	s.stats = stats
+	s.windowTicks++
+	if elapsed := time.Since(s.windowStart); elapsed >= time.Second {
+		s.tps = float64(s.windowTicks) / elapsed.Seconds()
+		s.windowStart = time.Now()
+		s.windowTicks = 0
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	header := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	// one line per sim, in a stable order
+	names := make([]string, 0, len(m.sims))
+	for name := range m.sims {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	each := func(f func(sim string, s *simMetrics)) {
+		for _, name := range names {
+			f(name, m.sims[name])
+		}
+	}
+
+	simple := func(name, kind, help string, value func(s *simMetrics) float64) {
+		header(name, kind, help)
+		each(func(sim string, s *simMetrics) {
+			fmt.Fprintf(&b, "%s{sim=%s} %v\n", name, label(sim), value(s))
+		})
+	}
+
+	simple("ants_ticks_total", "counter", "Simulated ticks.", func(s *simMetrics) float64 { return float64(s.stats.ticks) })
+	simple("ants_tps", "gauge", "Simulated ticks per second.", func(s *simMetrics) float64 { return s.tps })
+	simple("ants_alive", "gauge", "Living ants.", func(s *simMetrics) float64 { return float64(s.stats.ants.alive) })
+	simple("ants_born_total", "counter", "Ants spawned by the colony.", func(s *simMetrics) float64 { return float64(s.stats.ants.born) })
+	simple("ants_died_total", "counter", "Ants that died, including those killed.", func(s *simMetrics) float64 { return float64(s.stats.ants.died) })
+	simple("ants_killed_total", "counter", "Ants killed by hazards and predators.", func(s *simMetrics) float64 { return float64(s.stats.ants.killed) })
+	simple("ants_food_left", "gauge", "Food remaining in the world.", func(s *simMetrics) float64 { return float64(s.stats.food.left) })
+	simple("ants_food_collected_total", "counter", "Food returned to the hills.", func(s *simMetrics) float64 { return float64(s.stats.food.collected) })
+	simple("ants_food_stored", "gauge", "Collected food not yet spent on new ants.", func(s *simMetrics) float64 { return float64(s.stats.food.stored) })
+
+	header("ants_state", "gauge", "Ants by state.")
+	each(func(sim string, s *simMetrics) {
+		fmt.Fprintf(&b, "ants_state{sim=%s,state=\"foraging\"} %d\n", label(sim), s.stats.ants.foraging)
+		fmt.Fprintf(&b, "ants_state{sim=%s,state=\"returning\"} %d\n", label(sim), s.stats.ants.returning)
+	})
+
+	header("ants_pheromones", "gauge", "Pheromone marks by type.")
+	each(func(sim string, s *simMetrics) {
+		for _, p := range s.stats.pheromones {
+			fmt.Fprintf(&b, "ants_pheromones{sim=%s,type=%s} %d\n", label(sim), label(p.name), p.marks)
+		}
+	})
+
+	header("ants_score", "gauge", "Custom score from the scenario script.")
+	each(func(sim string, s *simMetrics) {
+		if s.stats.scored {
+			fmt.Fprintf(&b, "ants_score{sim=%s} %v\n", label(sim), s.stats.score)
+		}
+	})
+
+	header("ants_caste_ants", "gauge", "Living ants by caste.")
+	each(func(sim string, s *simMetrics) {
+		for _, c := range s.stats.castes {
+			fmt.Fprintf(&b, "ants_caste_ants{sim=%s,caste=%s} %d\n", label(sim), label(c.name), c.ants)
+		}
+	})
+
+	header("ants_caste_collected_total", "counter", "Food collected by caste.")
+	each(func(sim string, s *simMetrics) {
+		for _, c := range s.stats.castes {
+			fmt.Fprintf(&b, "ants_caste_collected_total{sim=%s,caste=%s} %d\n", label(sim), label(c.name), c.collected)
+		}
+	})
+
+	header("ants_patch_food_left", "gauge", "Food remaining by patch.")
+	each(func(sim string, s *simMetrics) {
+		for _, p := range s.stats.patches {
+			fmt.Fprintf(&b, "ants_patch_food_left{sim=%s,patch=%s} %d\n", label(sim), label(p.name), p.left)
+		}
+	})
+
+	header("ants_patch_collected_total", "counter", "Food collected by patch.")
+	each(func(sim string, s *simMetrics) {
+		for _, p := range s.stats.patches {
+			fmt.Fprintf(&b, "ants_patch_collected_total{sim=%s,patch=%s} %d\n", label(sim), label(p.name), p.collected)
+		}
+	})
+
+	header("ants_tick_duration_seconds", "histogram", "Wall time spent simulating a tick.")
+	var cumulative uint64
+	for i, le := range tickBuckets {
+		cumulative += m.tickCounts[i]
+		fmt.Fprintf(&b, "ants_tick_duration_seconds_bucket{le=\"%v\"} %d\n", le, cumulative)
+	}
+	fmt.Fprintf(&b, "ants_tick_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.tickCount)
+	fmt.Fprintf(&b, "ants_tick_duration_seconds_sum %v\n", m.tickSum)
+	fmt.Fprintf(&b, "ants_tick_duration_seconds_count %d\n", m.tickCount)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes a label value.
+func label(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := m.WriteTo(w); err != nil {
+		log.Printf("error writing metrics: %v", err)
+	}
+}
+
+// serveMetrics serves the metrics on addr at /metrics in the background.
+func serveMetrics(addr string, m *Metrics) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+
+	go func() {
+		if err := http.Serve(lis, mux); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("serving metrics on http://%s/metrics", lis.Addr())
+	return nil
+}
+
+// observeTick reports a finished tick to the games metrics, if any.
+func (g *Game) observeTick(start time.Time) {
+	if g.metrics != nil {
+		g.metrics.observe(g.metricsSim, g.Stats(), time.Since(start))
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+
+	params := DefaultParams
+	params.AntCount = 10
+	game := NewGame(&params, nil)
+	game.metrics = metrics
+	game.metricsSim = "main"
+
+	for range 3 {
+		require.NoError(t, game.Update())
+	}
+
+	server := httptest.NewServer(metrics)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Contains(t, string(body), "# TYPE ants_ticks_total counter\n")
+	require.Contains(t, string(body), `ants_ticks_total{sim="main"} 3`)
+	require.Contains(t, string(body), `ants_alive{sim="main"} 10`)
+	require.Contains(t, string(body), `ants_patch_food_left{sim="main",patch="top left"} 15000`)
+	require.Contains(t, string(body), `ants_tick_duration_seconds_bucket{le="+Inf"} 3`)
+	require.Contains(t, string(body), "ants_tick_duration_seconds_count 3\n")
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	require.Equal(t, `"a \"b\" \\c\n"`, label("a \"b\" \\c\n"))
+}
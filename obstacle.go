@@ -1,7 +1,228 @@
 package main
 
-import "github.com/rafibayer/ants-again/vector"
+import (
+	"math"
+	"slices"
 
+	"github.com/rafibayer/ants-again/spatial"
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// Obstacle is a solid point, drawn as a square of OBSTACLE_CELL_SIZE.
+// ants are pushed away from nearby obstacles.
 type Obstacle struct {
 	vector.Vector
 }
+
+// Wall is a solid line segment from A to B that ants can't pass through.
+type Wall struct {
+	A, B vector.Vector
+
+	polygon *Polygon // owning polygon, nil for freestanding walls
+	pieces  []*wallPiece
+}
+
+// wallPiece is a section of a wall at most WALL_PIECE_LENGTH long.
+// walls are split into pieces so they can be stored by midpoint in a spatial structure,
+// while still being found by a radial search around any point they pass through.
+type wallPiece struct {
+	// midpoint
+	vector.Vector
+
+	a, b vector.Vector
+	wall *Wall
+}
+
+// Polygon is a solid closed shape, its edges are walls.
+type Polygon struct {
+	Vertices []vector.Vector
+
+	walls []*Wall
+}
+
+type WallMode int
+
+const (
+	WallReflect WallMode = iota
+	WallSlide
+)
+
+var wallModes = []string{"reflect", "slide"}
+
+// OcclusionMode controls whether walls and obstacles block pheromone sensing.
+type OcclusionMode int
+
+const (
+	OcclusionOff       OcclusionMode = iota
+	OcclusionIgnore                  // pheromones out of sight are not sensed
+	OcclusionAttenuate               // pheromones out of sight are scaled by PheromoneOcclusionAttenuation
+)
+
+var occlusionModes = []string{"off", "ignore", "attenuate"}
+
+func (g *Game) addWall(a, b vector.Vector) *Wall {
+	w := &Wall{A: a, B: b}
+	g.insertWall(w)
+	return w
+}
+
+func (g *Game) insertWall(w *Wall) {
+	n := max(1, int(math.Ceil(w.A.Distance(w.B)/WALL_PIECE_LENGTH)))
+	step := w.B.Sub(w.A).Mul(1.0 / float64(n))
+
+	w.pieces = make([]*wallPiece, 0, n)
+	for i := range n {
+		a := w.A.Add(step.Mul(float64(i)))
+		b := a.Add(step)
+		if i == n-1 {
+			b = w.B // avoid accumulating error at the far end
+		}
+
+		piece := &wallPiece{Vector: a.Add(b).Mul(0.5), a: a, b: b, wall: w}
+		w.pieces = append(w.pieces, piece)
+		g.walls.Insert(piece)
+	}
+}
+
+// removeWall removes a wall, or its whole polygon if it has one.
+func (g *Game) removeWall(w *Wall) {
+	if w.polygon != nil {
+		g.removePolygon(w.polygon)
+		return
+	}
+
+	g.deleteWall(w)
+}
+
+func (g *Game) deleteWall(w *Wall) {
+	for _, piece := range w.pieces {
+		spatial.RemoveSame(g.walls, piece)
+	}
+}
+
+func (g *Game) addPolygon(vertices []vector.Vector) *Polygon {
+	p := &Polygon{Vertices: vertices}
+	g.insertPolygon(p)
+	return p
+}
+
+func (g *Game) insertPolygon(p *Polygon) {
+	p.walls = make([]*Wall, 0, len(p.Vertices))
+	for i, a := range p.Vertices {
+		b := p.Vertices[(i+1)%len(p.Vertices)]
+		w := &Wall{A: a, B: b, polygon: p}
+		g.insertWall(w)
+		p.walls = append(p.walls, w)
+	}
+
+	g.polygons = append(g.polygons, p)
+}
+
+func (g *Game) removePolygon(p *Polygon) {
+	for _, w := range p.walls {
+		g.deleteWall(w)
+	}
+
+	g.polygons = slices.DeleteFunc(g.polygons, func(other *Polygon) bool {
+		return other == p
+	})
+}
+
+// wallsNear returns the distinct walls passing within radius of v.
+func (g *Game) wallsNear(v vector.Vector, radius float64) []*Wall {
+	walls := []*Wall{}
+	for piece := range g.walls.RadialSearchIter(v, radius+WALL_PIECE_LENGTH/2) {
+		if vector.DistanceToSegment(v, piece.a, piece.b) <= radius && !slices.Contains(walls, piece.wall) {
+			walls = append(walls, piece.wall)
+		}
+	}
+
+	return walls
+}
+
+// collideWalls checks a move from -> to against nearby walls.
+// if a wall is crossed, returns the unit normal of the first wall hit, facing back towards from.
+func (g *Game) collideWalls(from, to vector.Vector) (vector.Vector, bool) {
+	mid := from.Add(to).Mul(0.5)
+	reach := from.Distance(to)/2 + WALL_PIECE_LENGTH/2
+
+	first := math.Inf(1)
+	normal := vector.ZERO
+	for piece := range g.walls.RadialSearchIter(mid, reach) {
+		t, ok := vector.SegmentIntersection(from, to, piece.a, piece.b)
+
+		// t == 0 means we're starting on the wall (e.g. it was drawn on top of us), let us leave.
+		if !ok || t == 0 || t >= first {
+			continue
+		}
+
+		first = t
+		normal = piece.b.Sub(piece.a).Perpendicular().Normalize()
+		if normal.Dot(to.Sub(from)) > 0 {
+			normal = normal.Mul(-1)
+		}
+	}
+
+	return normal, !math.IsInf(first, 1)
+}
+
+// occluders are the walls and obstacles around a point, collected once for repeated line of sight checks.
+type occluders struct {
+	walls     []*wallPiece
+	obstacles []*Obstacle
+}
+
+func (g *Game) occludersNear(v vector.Vector, radius float64) occluders {
+	return occluders{
+		walls:     g.walls.RadialSearch(v, radius+WALL_PIECE_LENGTH/2),
+		obstacles: g.obstacles.RadialSearch(v, radius+OBSTACLE_CELL_SIZE*math.Sqrt2),
+	}
+}
+
+// blocks reports whether the line of sight from -> to is interrupted by a wall or obstacle.
+func (o occluders) blocks(from, to vector.Vector) bool {
+	for _, piece := range o.walls {
+		if _, ok := vector.SegmentIntersection(from, to, piece.a, piece.b); ok {
+			return true
+		}
+	}
+
+	for _, obs := range o.obstacles {
+		// obstacles position represented by top left of square
+		corner := obs.Add(vector.Vector{X: OBSTACLE_CELL_SIZE, Y: OBSTACLE_CELL_SIZE})
+		if vector.SegmentIntersectsRect(from, to, obs.Vector, corner) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// moveAnt moves the ant by step, unless it would cross a wall.
+// on collision the ant reflects off of, or slides along the wall depending on the wall mode.
+func (g *Game) moveAnt(ant *Ant, step vector.Vector) {
+	destination := ant.Add(step)
+
+	normal, hit := g.collideWalls(ant.Vector, destination)
+	if !hit {
+		ant.Vector = destination
+		return
+	}
+
+	if WallMode(g.params.WallModeIndex) == WallSlide {
+		// drop the component of the step going into the wall
+		slide := step.Sub(normal.Mul(step.Dot(normal)))
+
+		// head-on collisions have nothing to slide along, fall back to reflecting
+		if slide.Magnitude() > step.Magnitude()/10 {
+			ant.dir = slide.Normalize()
+			destination = ant.Add(slide)
+			if _, hit := g.collideWalls(ant.Vector, destination); !hit {
+				ant.Vector = destination
+			}
+			return
+		}
+	}
+
+	ant.dir = ant.dir.Sub(normal.Mul(2 * ant.dir.Dot(normal)))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWallsBlockAnts(t *testing.T) {
+	for mode := range wallModes {
+		game := newTestGame(Scenario{}, func(p *Params) {
+			p.WallModeIndex = mode
+			p.AntCount = 200
+		})
+
+		// a box around the hill, and a thin diagonal through it
+		game.addPolygon([]vector.Vector{{X: 450, Y: 450}, {X: 550, Y: 450}, {X: 550, Y: 550}, {X: 450, Y: 550}})
+		game.addWall(vector.Vector{X: 460, Y: 460}, vector.Vector{X: 540, Y: 540})
+
+		prev := make([]vector.Vector, len(game.ants))
+		for range 300 {
+			for i, ant := range game.ants {
+				prev[i] = ant.Vector
+			}
+
+			game.updateAnts()
+
+			for i, ant := range game.ants {
+				for piece := range game.walls.PointsIter() {
+					// ants starting on a wall are allowed to leave it
+					at, crossed := vector.SegmentIntersection(prev[i], ant.Vector, piece.a, piece.b)
+					require.False(t, crossed && at > 0, "%s: ant moved %v -> %v through wall %v -> %v", wallModes[mode], prev[i], ant.Vector, piece.a, piece.b)
+				}
+			}
+		}
+	}
+}
+
+func TestWallsNear(t *testing.T) {
+	game := NewGame(nil, &Scenario{Hills: []vector.Vector{{X: 500, Y: 500}}})
+	wall := game.addWall(vector.Vector{X: 100, Y: 100}, vector.Vector{X: 300, Y: 100})
+	poly := game.addPolygon([]vector.Vector{{X: 100, Y: 200}, {X: 200, Y: 200}, {X: 150, Y: 300}})
+
+	require.Equal(t, []*Wall{wall}, game.wallsNear(vector.Vector{X: 200, Y: 105}, 10))
+	require.Empty(t, game.wallsNear(vector.Vector{X: 200, Y: 150}, 10))
+
+	near := game.wallsNear(vector.Vector{X: 150, Y: 200}, 1)
+	require.Len(t, near, 1)
+	require.Equal(t, poly, near[0].polygon)
+
+	game.removeWall(near[0])
+	require.Empty(t, game.polygons)
+	require.Equal(t, len(wall.pieces), game.walls.Len())
+}
+
+func TestOccluders(t *testing.T) {
+	game := NewGame(nil, &Scenario{Hills: []vector.Vector{{X: 500, Y: 500}}})
+	game.addWall(vector.Vector{X: 100, Y: 0}, vector.Vector{X: 100, Y: 200})
+	game.obstacles.Insert(&Obstacle{Vector: vector.Vector{X: 50, Y: 300}})
+
+	occ := game.occludersNear(vector.Vector{X: 50, Y: 100}, 100)
+	require.True(t, occ.blocks(vector.Vector{X: 50, Y: 100}, vector.Vector{X: 150, Y: 100}))
+	require.False(t, occ.blocks(vector.Vector{X: 50, Y: 100}, vector.Vector{X: 90, Y: 150}))
+
+	occ = game.occludersNear(vector.Vector{X: 55, Y: 250}, 100)
+	require.True(t, occ.blocks(vector.Vector{X: 55, Y: 250}, vector.Vector{X: 55, Y: 350}))
+	require.False(t, occ.blocks(vector.Vector{X: 55, Y: 250}, vector.Vector{X: 30, Y: 350}))
+}
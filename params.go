@@ -1,9 +1,23 @@
 package main
 
+import (
+	"fmt"
+	"slices"
+)
+
 type Params struct {
+	AntCount int // number of ants spawned at the hill (suggested: ANTS)
+
 	AntSpeed          float64 // ant movement per tick (suggested: 2.0)
 	AntRotation       float64 // random ant rotation in either direction per tick (suggested: 9.0)
 	AntPheromoneStart int     // ant pheromone "inventory" (suggested: 30)
+	AntRepellentStart int     // repellent pheromone "inventory" after finding exhausted food. 0 disables. (suggested: 5)
+	AntAlarmStart     int     // alarm pheromone "inventory" after being scared by a hazard or predator. 0 disables. (suggested: 5)
+	AntEnergy         float64 // distance an ant can walk before starving, refilled at the hill. 0 disables starvation. (suggested: GAME_SIZE * 3)
+	AntLifespan       int     // ticks an ant lives for. 0 is immortal. (suggested: 600 * TPS)
+
+	ColonySpawnCost int // collected food spent to spawn a new ant at a hill. 0 disables reproduction. (suggested: 5)
+	ColonyMaxAnts   int // population cap for reproduction. 0 is uncapped.
 
 	PheromoneSenseRadius           float64 // ant pheromone sense radius. expensive. (suggested: (game_size / 8.0))
 	PheromoneSenseCosineSimilarity float64 // if cost(theta) of angle between ant direction and pheromone is < this threshold, the pheromone is ignored. (suggested: 0.33)
@@ -12,21 +26,81 @@ type Params struct {
 	PheromoneInfluence             float64 // pheromone influence multiplier (suggested: 2.0)
 	PheromoneSenseProb             float64 // probability of an ant sensing pheromones per tick. expensive. (suggested: 1.0 / 4.0)
 
+	DepositModeIndex     int     // strength of dropped pheromones, see depositModes
+	DepositTripReference float64 // trip length in ticks that deposits strength 1.0 in trip length mode (suggested: 10 * TPS)
+
+	PheromoneOcclusionModeIndex   int     // whether walls and obstacles block pheromone sensing, see occlusionModes. expensive.
+	PheromoneOcclusionAttenuation float64 // strength multiplier for pheromones out of sight in attenuate mode (suggested: 0.1)
+
+	PathIntegration       bool    // returning ants steer towards the hill using their tracked displacement, blended with pheromones
+	PathIntegrationNoise  float64 // max angular error in degrees when measuring each step (suggested: 5.0)
+	PathIntegrationWeight float64 // home vector influence per tick (suggested: 0.25)
+
+	PredatorSpeed float64 // predator movement per tick (suggested: 2.0)
+
+	Behavior string // registered behavior steering the ants, see RegisterBehavior. empty is DEFAULT_BEHAVIOR.
+
+	Castes []Caste // ant castes, DefaultCastes if empty
+
 	BoundaryModeIndex int
+	WallModeIndex     int
 
 	// Debug Params
 	DebugDrawSensorRange bool
 }
 
+func (p Params) clone() *Params {
+	p.Castes = slices.Clone(p.Castes)
+	return &p
+}
+
+// validate range checks the params that can't be checked by their type alone.
+func (p *Params) validate() error {
+	if err := validBehavior(p.Behavior); err != nil {
+		return err
+	}
+
+	for _, mode := range []struct {
+		name  string
+		index int
+		modes []string
+	}{
+		{"DepositModeIndex", p.DepositModeIndex, depositModes},
+		{"PheromoneOcclusionModeIndex", p.PheromoneOcclusionModeIndex, occlusionModes},
+		{"BoundaryModeIndex", p.BoundaryModeIndex, boundaryModes},
+		{"WallModeIndex", p.WallModeIndex, wallModes},
+	} {
+		if mode.index < 0 || mode.index >= len(mode.modes) {
+			return fmt.Errorf("%s is %d, must be in [0, %d) for %v", mode.name, mode.index, len(mode.modes), mode.modes)
+		}
+	}
+
+	return validCastes(p.Castes)
+}
+
 // Default parameters if nil is passed to NewGame.
 var DefaultParams = Params{
+	AntCount:                       ANTS,
 	AntSpeed:                       1.8,
 	AntRotation:                    9.0,
 	AntPheromoneStart:              10,
+	AntRepellentStart:              0,
+	AntAlarmStart:                  0,
 	PheromoneSenseRadius:           GAME_SIZE / 10.0,
 	PheromoneSenseCosineSimilarity: 0.33,
 	PheromoneDecay:                 1.0 / (10 * TPS),
 	PheromoneDropProb:              1.0 / (TPS),
 	PheromoneInfluence:             3.0,
 	PheromoneSenseProb:             1.0 / 4,
+
+	DepositTripReference: 10 * TPS,
+
+	PheromoneOcclusionAttenuation: 0.1,
+
+	PathIntegrationNoise:  5.0,
+	PathIntegrationWeight: 0.25,
+
+	PredatorSpeed: 2.0,
+
+	Castes: slices.Clone(DefaultCastes),
 }
@@ -1,6 +1,12 @@
 package main
 
-import "github.com/rafibayer/ants-again/vector"
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/rafibayer/ants-again/spatial"
+	"github.com/rafibayer/ants-again/vector"
+)
 
 type Pheromone struct {
 	// position
@@ -9,28 +15,123 @@ type Pheromone struct {
 	amount float32
 }
 
-func (g *Game) updatePheromones() {
-	toRemove := make([]*Pheromone, 0)
-	for pher := range g.foragingPheromone.PointsIter() {
-		pher.amount -= g.params.PheromoneDecay
-		if pher.amount <= 0 {
-			toRemove = append(toRemove, pher)
+// PheromoneType indexes the games pheromone types and fields.
+// the built in types come first, scenarios can add their own after them.
+type PheromoneType int
+
+const (
+	PheromoneForaging  PheromoneType = iota // dropped by foraging ants, leads home
+	PheromoneReturning                      // dropped by returning ants, leads to food
+	PheromoneRepellent                      // "no food here", dropped by ants that reach exhausted food
+	PheromoneAlarm                          // "danger", dropped by ants scared by hazards and predators
+)
+
+// PheromoneKind describes the behavior of a type of pheromone.
+type PheromoneKind struct {
+	Name       string
+	Color      color.RGBA
+	DecayScale float32    // multiplies Params.PheromoneDecay
+	Sign       float64    // 1 attracts, -1 repels
+	SensedBy   []AntState // ant states that respond to this pheromone
+	DroppedBy  []AntState // ant states that lay this pheromone as their trail, repellent and alarm have their own triggers
+}
+
+// Default pheromone types if the scenario has none, indexed by PheromoneType.
+var DefaultPheromones = []PheromoneKind{
+	PheromoneForaging:  {Name: "foraging", Color: DARK_GREEN, DecayScale: 1, Sign: 1, SensedBy: []AntState{RETURN}, DroppedBy: []AntState{FORAGE}},
+	PheromoneReturning: {Name: "returning", Color: DARK_LILAC, DecayScale: 1, Sign: 1, SensedBy: []AntState{FORAGE}, DroppedBy: []AntState{RETURN}},
+	PheromoneRepellent: {Name: "repellent", Color: DARK_RED, DecayScale: 2, Sign: -1, SensedBy: []AntState{FORAGE}},
+	PheromoneAlarm:     {Name: "alarm", Color: ORANGE, DecayScale: 4, Sign: -1, SensedBy: []AntState{FORAGE, RETURN}},
+}
+
+// validPheromones checks a scenarios pheromone types, they must start with the built in types.
+func validPheromones(kinds []PheromoneKind) error {
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	if len(kinds) < len(DefaultPheromones) {
+		return fmt.Errorf("pheromones must start with the %d built in types, got %d", len(DefaultPheromones), len(kinds))
+	}
+
+	names := map[string]bool{}
+	for i, kind := range kinds {
+		if kind.Name == "" {
+			return fmt.Errorf("pheromone %d has no name", i)
+		}
+		if names[kind.Name] {
+			return fmt.Errorf("pheromone %q is defined twice", kind.Name)
+		}
+		names[kind.Name] = true
+
+		if kind.DecayScale <= 0 {
+			return fmt.Errorf("pheromone %q has decay scale %v, must be positive", kind.Name, kind.DecayScale)
 		}
 	}
 
-	for _, r := range toRemove {
-		g.foragingPheromone.Remove(r)
+	return nil
+}
+
+func (s *Scenario) pheromoneTypes() []PheromoneKind {
+	if len(s.Pheromones) == 0 {
+		return DefaultPheromones
 	}
 
-	toRemove = make([]*Pheromone, 0)
-	for pher := range g.returningPheromone.PointsIter() {
-		pher.amount -= g.params.PheromoneDecay
-		if pher.amount <= 0 {
-			toRemove = append(toRemove, pher)
+	return s.Pheromones
+}
+
+// DepositMode controls the strength of dropped pheromones.
+type DepositMode int
+
+const (
+	DepositConstant DepositMode = iota // every mark has strength 1.0
+	DepositGradient                    // marks weaken as the ant gets further from the start of its trip
+	DepositQuality                     // returning ants marks are scaled by the quality of the food they carry
+	DepositTrip                        // marks are scaled inversely by the ants previous trip length, so shorter routes win
+)
+
+var depositModes = []string{"constant", "gradient", "quality", "trip length"}
+
+// MAX_DEPOSIT caps the strength of a single pheromone mark
+const MAX_DEPOSIT = 3.0
+
+func (g *Game) depositStrength(ant *Ant) float32 {
+	switch DepositMode(g.params.DepositModeIndex) {
+	case DepositGradient:
+		return float32(ant.pheromoneStored) / float32(max(1, g.params.AntPheromoneStart))
+	case DepositQuality:
+		if ant.state == RETURN {
+			return min(MAX_DEPOSIT, ant.carrying)
 		}
+	case DepositTrip:
+		if ant.lastTrip > 0 {
+			return float32(min(MAX_DEPOSIT, g.params.DepositTripReference/float64(ant.lastTrip)))
+		}
+	default:
 	}
 
-	for _, r := range toRemove {
-		g.returningPheromone.Remove(r)
+	return 1.0
+}
+
+func (g *Game) updatePheromones() {
+	for kind, field := range g.pheromones {
+		decay := g.params.PheromoneDecay * g.pheromoneTypes[kind].DecayScale
+
+		toRemove := make([]*Pheromone, 0)
+		for pher := range field.PointsIter() {
+			pher.amount -= decay
+			if pher.amount <= 0 {
+				toRemove = append(toRemove, pher)
+			}
+		}
+
+		for _, r := range toRemove {
+			spatial.RemoveSame(field, r)
+		}
 	}
 }
+
+// dropPheromone deposits a pheromone of the given type at the ants position.
+func (g *Game) dropPheromone(kind PheromoneType, ant *Ant, amount float32) {
+	g.pheromones[kind].Insert(&Pheromone{Vector: &vector.Vector{X: ant.X, Y: ant.Y}, amount: amount})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositStrength(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 0
+	params.AntPheromoneStart = 10
+	params.DepositTripReference = 100
+	game := NewGame(&params, nil)
+
+	ant := &Ant{state: RETURN, pheromoneStored: 5, carrying: 2, lastTrip: 50}
+
+	game.params.DepositModeIndex = int(DepositConstant)
+	require.Equal(t, float32(1), game.depositStrength(ant))
+
+	game.params.DepositModeIndex = int(DepositGradient)
+	require.Equal(t, float32(0.5), game.depositStrength(ant))
+
+	game.params.DepositModeIndex = int(DepositQuality)
+	require.Equal(t, float32(2), game.depositStrength(ant))
+	ant.state = FORAGE
+	require.Equal(t, float32(1), game.depositStrength(ant))
+
+	game.params.DepositModeIndex = int(DepositTrip)
+	require.Equal(t, float32(2), game.depositStrength(ant))
+	ant.lastTrip = 1
+	require.Equal(t, float32(MAX_DEPOSIT), game.depositStrength(ant))
+	ant.lastTrip = 0
+	require.Equal(t, float32(1), game.depositStrength(ant))
+}
+
+func TestRepellentPheromone(t *testing.T) {
+	source := vector.Vector{X: 300, Y: 500}
+
+	// share of ant ticks spent at an exhausted source an old trail still leads to
+	atSource := func(repellent int, seed uint64) float64 {
+		util.Seed(seed)
+		game := newTestGame(Scenario{
+			Food: []FoodPatch{{X: source.X, Y: source.Y, Rows: 3, Cols: 3}},
+		}, func(p *Params) {
+			p.AntCount = 200
+			p.AntRepellentStart = repellent
+		})
+
+		for food := range game.food.PointsIter() {
+			food.amount = 0
+		}
+		for x := source.X; x <= 500; x += 5 {
+			game.pheromones[PheromoneReturning].Insert(&Pheromone{Vector: &vector.Vector{X: x, Y: 500}, amount: 1})
+		}
+		for _, ant := range game.ants {
+			ant.Vector = vector.Vector{X: 400, Y: 500}
+			ant.dir = vector.Vector{X: -1, Y: 0}
+		}
+
+		near := 0
+		for range 10 * TPS {
+			require.NoError(t, game.Update())
+			for _, ant := range game.ants {
+				if ant.Distance(source) < 50 {
+					near++
+				}
+			}
+		}
+
+		return float64(near) / float64(10*TPS*len(game.ants))
+	}
+
+	// ants warn each other off, so fewer of them linger
+	for seed := range uint64(3) {
+		require.Less(t, atSource(5, seed), atSource(0, seed), "seed %d", seed)
+	}
+}
+
+func TestCustomPheromone(t *testing.T) {
+	var trail PheromoneKind
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"Name": "trail", "Color": {"R": 255, "G": 255, "B": 255, "A": 255},
+		"DecayScale": 0.5, "Sign": 1, "SensedBy": ["forage"], "DroppedBy": ["forage"]
+	}`), &trail))
+	require.Equal(t, []AntState{FORAGE}, trail.DroppedBy)
+
+	data, err := json.Marshal(Scenario{
+		Hills:      []vector.Vector{{X: 500, Y: 500}},
+		Pheromones: append(slices.Clone(DefaultPheromones), trail),
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "trail.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	scenario, err := LoadScenario(path)
+	require.NoError(t, err)
+
+	params := DefaultParams
+	params.AntCount = 1
+	params.PheromoneDropProb = 1
+	game := NewGame(&params, scenario)
+	custom := PheromoneType(len(DefaultPheromones))
+
+	// foraging ants lay it along with their own trail
+	ant := game.ants[0]
+	game.updateAnts()
+	require.Equal(t, 1, game.pheromones[custom].Len())
+	require.Equal(t, 1, game.pheromones[PheromoneForaging].Len())
+	require.Contains(t, game.Stats().metrics(), metric{"pheromone.trail", 1})
+
+	// and follow it
+	game.pheromones[custom].Insert(&Pheromone{Vector: &vector.Vector{X: ant.X + 10, Y: ant.Y + 5}, amount: 1})
+	ant.dir = vector.Vector{X: 1, Y: 0}
+	pull := game.sensePheromones(ant, params.PheromoneSenseRadius)
+	require.Greater(t, pull.Y, 0.0)
+
+	// at its own decay rate
+	game.updatePheromones()
+	for pher := range game.pheromones[custom].PointsIter() {
+		require.InDelta(t, 1-params.PheromoneDecay*0.5, pher.amount, 1e-6)
+	}
+}
+
+func TestInvalidPheromones(t *testing.T) {
+	require.ErrorContains(t, validPheromones(DefaultPheromones[:2]), "built in")
+
+	dup := append(slices.Clone(DefaultPheromones), DefaultPheromones[0])
+	require.ErrorContains(t, validPheromones(dup), "defined twice")
+
+	var state AntState
+	require.Error(t, json.Unmarshal([]byte(`"sleeping"`), &state))
+}
+
+func TestPheromoneSharedPosition(t *testing.T) {
+	game := newTestGame(Scenario{}, nil)
+
+	// a held ant drops fresh marks on top of old ones
+	field := game.pheromones[PheromoneForaging]
+	fresh := &Pheromone{Vector: &vector.Vector{X: 100, Y: 100}, amount: 1}
+	field.Insert(fresh)
+	field.Insert(&Pheromone{Vector: &vector.Vector{X: 100, Y: 100}, amount: game.params.PheromoneDecay / 2})
+
+	game.updatePheromones()
+	require.Equal(t, 1, field.Len())
+	require.Same(t, fresh, field.Points()[0])
+	require.Greater(t, fresh.amount, float32(0))
+}
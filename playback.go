@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	SPEED_MIN = 0.25 // sim ticks per frame
+	SPEED_MAX = 16.0
+
+	FAST_FORWARD_TICKS  = 60 * TPS                  // default fast-forward length
+	FAST_FORWARD_BUDGET = time.Second / TPS * 3 / 4 // time spent ticking per frame while fast-forwarding, the rest is left to drawing
+)
+
+// playback hotkeys:
+//
+//	space  pause / resume
+//	.      step one tick, pausing
+//	[ ]    halve / double the speed
+//	f      fast-forward, press again to stop
+func (g *Game) pollPlaybackKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.setPaused(!g.paused)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.step(1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+		g.setSpeed(g.speed / 2)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+		g.setSpeed(g.speed * 2)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		if g.fastForwardTicks > 0 {
+			g.fastForwardTicks = 0
+		} else {
+			g.fastForward(g.fastForwardLength)
+		}
+	}
+}
+
+func (g *Game) setPaused(paused bool) {
+	g.paused = paused
+	g.stepTicks = 0
+}
+
+// step runs n more ticks then pauses, one tick per frame so stepping is visible.
+func (g *Game) step(n int) {
+	g.paused = true
+	g.stepTicks += n
+}
+
+func (g *Game) setSpeed(speed float64) {
+	g.speed = min(max(speed, SPEED_MIN), SPEED_MAX)
+}
+
+// fastForward runs n ticks as fast as possible, ignoring pause and speed until done.
+func (g *Game) fastForward(n int) {
+	g.fastForwardTicks += n
+}
+
+// ticksThisFrame returns the number of ticks to run this frame, at most one while paused.
+// fractional speeds accumulate across frames, so 0.5 ticks every other frame.
+func (g *Game) ticksThisFrame() int {
+	if g.paused {
+		n := min(g.stepTicks, 1, g.ticksLeft())
+		g.stepTicks -= n
+		return n
+	}
+
+	g.tickDebt += g.speed
+	n := int(g.tickDebt)
+	g.tickDebt -= float64(n)
+	return min(n, g.ticksLeft())
+}
+
+// ticksLeft returns the ticks that can run before the tick limit.
+func (g *Game) ticksLeft() int {
+	if g.tickLimit == 0 {
+		return math.MaxInt
+	}
+
+	return max(0, g.tickLimit-g.tickCount)
+}
+
+// updateFastForward ticks until the fast-forward is done or the frames budget is spent.
+func (g *Game) updateFastForward() error {
+	start := time.Now()
+	for g.fastForwardTicks > 0 && g.ticksLeft() > 0 && time.Since(start) < FAST_FORWARD_BUDGET {
+		if err := g.tick(); err != nil {
+			return err
+		}
+		g.fastForwardTicks--
+	}
+
+	return nil
+}
+
+func (g *Game) playbackStatus() string {
+	switch {
+	case g.fastForwardTicks > 0:
+		return fmt.Sprintf("tick %d, fast-forwarding (%d left)", g.tickCount, g.fastForwardTicks)
+	case g.paused:
+		return fmt.Sprintf("tick %d, paused", g.tickCount)
+	default:
+		return fmt.Sprintf("tick %d, %gx", g.tickCount, g.speed)
+	}
+}
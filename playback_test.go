@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeed(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 1
+	game := NewGame(&params, nil)
+
+	game.setSpeed(2)
+	require.NoError(t, game.Update())
+	require.Equal(t, 2, game.tickCount)
+
+	// fractional speeds carry over between frames
+	game.setSpeed(0.5)
+	for range 4 {
+		require.NoError(t, game.Update())
+	}
+	require.Equal(t, 4, game.tickCount)
+
+	game.setSpeed(1000)
+	require.Equal(t, SPEED_MAX, game.speed)
+	game.setSpeed(0)
+	require.Equal(t, SPEED_MIN, game.speed)
+}
+
+func TestPauseAndStep(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 1
+	game := NewGame(&params, nil)
+
+	game.setPaused(true)
+	require.NoError(t, game.Update())
+	require.Equal(t, 0, game.tickCount)
+
+	// steps run one per frame, regardless of speed
+	game.setSpeed(4)
+	game.step(2)
+	for range 3 {
+		require.NoError(t, game.Update())
+	}
+	require.Equal(t, 2, game.tickCount)
+	require.True(t, game.paused)
+
+	game.setPaused(false)
+	require.NoError(t, game.Update())
+	require.Equal(t, 6, game.tickCount)
+}
+
+func TestFastForward(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 1
+	game := NewGame(&params, nil)
+	game.setPaused(true)
+
+	game.fastForward(100)
+	require.Contains(t, game.playbackStatus(), "fast-forwarding")
+	for game.fastForwardTicks > 0 {
+		require.NoError(t, game.Update())
+	}
+	require.Equal(t, 100, game.tickCount)
+
+	// back to paused once done
+	require.NoError(t, game.Update())
+	require.Equal(t, 100, game.tickCount)
+	require.Contains(t, game.playbackStatus(), "paused")
+}
+
+func TestTickLimit(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 1
+	game := NewGame(&params, nil)
+	game.tickLimit = 5
+
+	game.setSpeed(4)
+	require.NoError(t, game.Update())
+	require.NoError(t, game.Update())
+	require.Equal(t, 5, game.tickCount)
+
+	game.fastForward(100)
+	require.NoError(t, game.Update())
+	require.Equal(t, 5, game.tickCount)
+
+	game.fastForwardTicks = 0
+	game.step(3)
+	require.NoError(t, game.Update())
+	require.Equal(t, 5, game.tickCount)
+}
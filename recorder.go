@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const RECORD_EVERY = TPS // default ticks between samples
+
+// Recorder samples Stats at a fixed tick interval into a time series,
+// for plotting colony performance over time.
+type Recorder struct {
+	every int
+
+	columns []string // in order of first appearance
+	seen    map[string]bool
+	rows    []map[string]float64
+
+	lastCollected int
+}
+
+func NewRecorder(every int) *Recorder {
+	return &Recorder{every: max(1, every), seen: map[string]bool{}}
+}
+
+// metric is a single named value in a sample.
+type metric struct {
+	name  string
+	value float64
+}
+
+// Record appends a sample of the games stats.
+func (r *Recorder) Record(s *Stats) {
+	// collection rate over the last interval, in food per simulated minute
+	rate := 0.0
+	if len(r.rows) > 0 {
+		rate = float64(s.food.collected-r.lastCollected) / float64(r.every) * 60 * TPS
+	}
+	r.lastCollected = s.food.collected
+
+	row := map[string]float64{}
+	for _, m := range append(s.metrics(), metric{"food.rate", rate}) {
+		if !r.seen[m.name] {
+			r.seen[m.name] = true
+			r.columns = append(r.columns, m.name)
+		}
+		row[m.name] = m.value
+	}
+
+	r.rows = append(r.rows, row)
+}
+
+// metrics flattens the stats into named values.
+// caste and patch names are part of the metric name, so patches added mid-run become new columns.
+func (s *Stats) metrics() []metric {
+	metrics := []metric{
+		{"ticks", float64(s.ticks)},
+		{"ants.alive", float64(s.ants.alive)},
+		{"ants.foraging", float64(s.ants.foraging)},
+		{"ants.returning", float64(s.ants.returning)},
+		{"ants.born", float64(s.ants.born)},
+		{"ants.died", float64(s.ants.died)},
+		{"ants.killed", float64(s.ants.killed)},
+		{"food.left", float64(s.food.left)},
+		{"food.collected", float64(s.food.collected)},
+		{"food.stored", float64(s.food.stored)},
+	}
+
+	for _, p := range s.pheromones {
+		metrics = append(metrics, metric{"pheromone." + p.name, float64(p.marks)})
+	}
+
+	if s.scored {
+		metrics = append(metrics, metric{"score", s.score})
+	}
+
+	for _, c := range s.castes {
+		metrics = append(metrics,
+			metric{"caste." + c.name + ".ants", float64(c.ants)},
+			metric{"caste." + c.name + ".collected", float64(c.collected)},
+		)
+	}
+
+	for _, p := range s.patches {
+		metrics = append(metrics,
+			metric{"patch." + p.name + ".left", float64(p.left)},
+			metric{"patch." + p.name + ".collected", float64(p.collected)},
+		)
+	}
+
+	return metrics
+}
+
+// WriteCSV writes the time series with a header row. values missing from a sample are left empty.
+func (r *Recorder) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(r.columns))
+	for _, row := range r.rows {
+		for i, col := range r.columns {
+			record[i] = ""
+			if v, ok := row[col]; ok {
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the time series as an array of samples, one object per sample.
+func (r *Recorder) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.rows)
+}
+
+// Save writes the time series to path, as CSV or JSON depending on its extension.
+func (r *Recorder) Save(path string) error {
+	write, err := recordFormat(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating stats file: %w", err)
+	}
+
+	if err := write(r, f); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing stats: %w", err)
+	}
+
+	return f.Close()
+}
+
+// recordFormat returns the writer for paths stats format, by extension.
+func recordFormat(path string) (func(*Recorder, io.Writer) error, error) {
+	switch filepath.Ext(path) {
+	case ".csv":
+		return (*Recorder).WriteCSV, nil
+	case ".json":
+		return (*Recorder).WriteJSON, nil
+	default:
+		return nil, fmt.Errorf("unsupported stats format %q, use .csv or .json", filepath.Ext(path))
+	}
+}
+
+// checkRecordPath checks stats can be saved to path, so a bad --record fails before a long run instead of after it.
+func checkRecordPath(path string) error {
+	if _, err := recordFormat(path); err != nil {
+		return err
+	}
+
+	// try writing next to it, without touching a previous recording
+	f, err := os.CreateTemp(filepath.Dir(path), ".record-*")
+	if err != nil {
+		return fmt.Errorf("can't save stats to %s: %w", path, err)
+	}
+	f.Close()
+
+	return os.Remove(f.Name())
+}
+
+// record samples the games stats if it's being recorded and a sample is due.
+// it runs after the tick is counted, so a sample labelled N holds the state after N ticks.
+func (g *Game) record() {
+	if g.recorder != nil && g.tickCount%g.recorder.every == 0 {
+		g.recorder.Record(g.Stats())
+	}
+}
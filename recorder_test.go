@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	params := DefaultParams
+	params.AntCount = 10
+	game := NewGame(&params, nil)
+	game.recorder = NewRecorder(5)
+
+	for range 12 {
+		require.NoError(t, game.Update())
+	}
+
+	// after 5 and 10 ticks
+	var buf bytes.Buffer
+	require.NoError(t, game.recorder.WriteCSV(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, "ticks", records[0][0])
+	require.Contains(t, records[0], "patch.top left.collected")
+	require.Equal(t, []string{"5", "10"}, []string{records[1][0], records[2][0]})
+
+	buf.Reset()
+	require.NoError(t, game.recorder.WriteJSON(&buf))
+
+	var samples []map[string]float64
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &samples))
+	require.Len(t, samples, 2)
+	require.Equal(t, 10.0, samples[1]["ants.alive"])
+}
+
+func TestRecorderNewColumns(t *testing.T) {
+	game := newTestGame(Scenario{
+		Events: []Event{{Tick: 1, SpawnFood: &FoodPatch{Name: "late", X: 100, Y: 100, Rows: 1, Cols: 1}}},
+	}, nil)
+	game.recorder = NewRecorder(1)
+
+	for range 3 {
+		require.NoError(t, game.Update())
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, game.recorder.WriteCSV(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+
+	// the patch spawns during the second tick, so the first sample doesn't have it
+	col := -1
+	for i, name := range records[0] {
+		if name == "patch.late.left" {
+			col = i
+		}
+	}
+	require.NotEqual(t, -1, col)
+	require.Equal(t, []string{"1", ""}, []string{records[1][0], records[1][col]})
+	require.Equal(t, []string{"2", "50"}, []string{records[2][0], records[2][col]}) // FOOD_START
+}
+
+func TestRecorderSave(t *testing.T) {
+	r := NewRecorder(1)
+	r.Record(NewGame(nil, nil).Stats())
+
+	dir := t.TempDir()
+	require.NoError(t, r.Save(filepath.Join(dir, "stats.csv")))
+	require.NoError(t, r.Save(filepath.Join(dir, "stats.json")))
+	require.Error(t, r.Save(filepath.Join(dir, "stats.txt")))
+
+	_, err := os.Stat(filepath.Join(dir, "stats.json"))
+	require.NoError(t, err)
+}
+
+func TestCheckRecordPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, checkRecordPath(filepath.Join(dir, "stats.csv")))
+	require.ErrorContains(t, checkRecordPath(filepath.Join(dir, "stats.txt")), "unsupported stats format")
+	require.ErrorContains(t, checkRecordPath(filepath.Join(dir, "missing", "stats.json")), "can't save stats")
+
+	// the check leaves nothing behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
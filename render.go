@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/rafibayer/ants-again/spatial"
+	vec "github.com/rafibayer/ants-again/vector"
 
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -54,10 +56,12 @@ func drawWorldSpace(g *Game) {
 	g.drawPheromones()
 	// g.naiveDrawPheromones()
 
+	g.drawHazards()
 	g.drawAnts()
 	g.drawFood()
 	g.drawHills()
 	g.drawObstacles()
+	g.drawSelection()
 
 	// game world bounding box
 	vector.StrokeRect(g.world, 0, 0, GAME_SIZE, GAME_SIZE, 5, color.White, false)
@@ -84,7 +88,7 @@ func (g *Game) drawAnts() {
 
 func (g *Game) drawFood() {
 	for food := range g.food.PointsIter() {
-		c := Fade(BROWN, float32(food.amount)/float32(FOOD_START))
+		c := Fade(BROWN, float32(food.amount)/float32(max(1, food.capacity)))
 		vector.FillRect(g.world, float32(food.X), float32(food.Y), ANT_FOOD_RADIUS, ANT_FOOD_RADIUS, c, true)
 	}
 }
@@ -95,16 +99,31 @@ func (g *Game) drawHills() {
 	}
 }
 
+func (g *Game) drawHazards() {
+	for h := range g.hazards.PointsIter() {
+		c := ORANGE
+		if h.lethal {
+			c = RED
+		}
+		vector.StrokeCircle(g.world, float32(h.X), float32(h.Y), float32(h.radius), 2, c, true)
+	}
+
+	for _, p := range g.predators {
+		vector.FillCircle(g.world, float32(p.X), float32(p.Y), float32(PREDATOR_KILL_RADIUS*2), RED, true)
+	}
+}
+
 func (g *Game) drawPheromones() {
-	// Clear buffer to black (or background color)
-	for i := range g.px {
-		g.px[i] = 0
+	// Clear buffer to the terrain background
+	if g.background == nil {
+		g.background = g.terrainPixels()
 	}
+	copy(g.px, g.background)
 
 	writePheromones := func(ph spatial.Spatial[*Pheromone], color color.RGBA) {
 		for pher := range ph.PointsIter() {
-			// Fade color by pheromone amount (0..1)
-			c := Fade(color, pher.amount)
+			// Fade color by pheromone amount (0..1), stronger deposits are clamped
+			c := Fade(color, min(1, pher.amount))
 
 			x := int(pher.X)
 			y := int(pher.Y)
@@ -120,30 +139,116 @@ func (g *Game) drawPheromones() {
 		}
 	}
 
-	writePheromones(g.foragingPheromone, DARK_GREEN)
-	writePheromones(g.returningPheromone, DARK_LILAC)
+	for kind, field := range g.pheromones {
+		writePheromones(field, g.pheromoneTypes[kind].Color)
+	}
 
 	// Write the pixel buffer to the ebiten.Image once
 	g.world.WritePixels(g.px)
 }
 
-func (g *Game) naiveDrawPheromones() {
-	for pher := range g.foragingPheromone.PointsIter() {
-		c := Fade(DARK_GREEN, pher.amount)
-		vector.FillRect(g.world, float32(pher.X), float32(pher.Y), 3.0, 3.0, c, false)
+// terrainPixels renders the terrain, slow ground is muddy brown and fast ground is light gray.
+// normal ground is left black.
+func (g *Game) terrainPixels() []byte {
+	px := make([]byte, GAME_SIZE*GAME_SIZE*4)
+	for y := range GAME_SIZE {
+		for x := range GAME_SIZE {
+			speed := g.terrain.Speed(vec.Vector{X: float64(x), Y: float64(y)})
+
+			var c color.RGBA
+			switch {
+			case speed < 1:
+				c = Fade(BROWN, float32(0.5*(1-speed)))
+			case speed > 1:
+				c = Fade(GRAY, float32(0.3*min(1, (speed-1)/(TERRAIN_IMAGE_SCALE-1))))
+			default:
+				continue
+			}
+
+			idx := 4 * (y*GAME_SIZE + x)
+			px[idx+0] = c.R
+			px[idx+1] = c.G
+			px[idx+2] = c.B
+			px[idx+3] = 255
+		}
 	}
 
-	for pher := range g.returningPheromone.PointsIter() {
-		c := Fade(DARK_LILAC, pher.amount)
-		vector.FillRect(g.world, float32(pher.X), float32(pher.Y), 3.0, 3.0, c, false)
+	return px
+}
+
+func (g *Game) naiveDrawPheromones() {
+	for kind, field := range g.pheromones {
+		for pher := range field.PointsIter() {
+			c := Fade(g.pheromoneTypes[kind].Color, min(1, pher.amount))
+			vector.FillRect(g.world, float32(pher.X), float32(pher.Y), 3.0, 3.0, c, false)
+		}
 	}
 }
 
 func (g *Game) drawObstacles() {
 	for obs := range g.obstacles.PointsIter() {
 		// obstacles position represented by top left of square
-		vector.FillRect(g.world, float32(obs.X), float32(obs.Y), OBSTACLE_HASH_CELL_SIZE, OBSTACLE_HASH_CELL_SIZE, GRAY, false)
+		vector.FillRect(g.world, float32(obs.X), float32(obs.Y), OBSTACLE_CELL_SIZE, OBSTACLE_CELL_SIZE, GRAY, false)
+	}
+
+	for _, p := range g.polygons {
+		fillPolygon(g.world, p.Vertices, GRAY)
+	}
+
+	for piece := range g.walls.PointsIter() {
+		vector.StrokeLine(g.world, float32(piece.a.X), float32(piece.a.Y), float32(piece.b.X), float32(piece.b.Y), WALL_WIDTH, GRAY, true)
+	}
+
+	// drafts in progress
+	if g.wallStart != nil {
+		vector.StrokeLine(g.world, float32(g.wallStart.X), float32(g.wallStart.Y), float32(g.cursor.X), float32(g.cursor.Y), WALL_WIDTH, WHITE, true)
 	}
+
+	if g.shapeStart != nil {
+		a, b := *g.shapeStart, g.cursor
+		switch BrushShape(g.brushShapeIndex) {
+		case BrushLine:
+			vector.StrokeLine(g.world, float32(a.X), float32(a.Y), float32(b.X), float32(b.Y), float32(max(g.brushRadius*2, WALL_WIDTH)), WHITE, true)
+		case BrushRectangle:
+			vector.StrokeRect(g.world, float32(min(a.X, b.X)), float32(min(a.Y, b.Y)), float32(math.Abs(a.X-b.X)), float32(math.Abs(a.Y-b.Y)), WALL_WIDTH, WHITE, true)
+		case BrushCircle:
+			vector.StrokeCircle(g.world, float32(a.X), float32(a.Y), float32(a.Distance(b)), WALL_WIDTH, WHITE, true)
+		default:
+		}
+	}
+
+	// brush outline
+	switch CursorMode(g.cursorModeIndex) {
+	case CursorModeFood, CursorModeObstacle, CursorModeAnts:
+		if g.brushRadius > 0 {
+			vector.StrokeCircle(g.world, float32(g.cursor.X), float32(g.cursor.Y), float32(g.brushRadius), 1, WHITE, true)
+		}
+	default:
+	}
+
+	for i, v := range g.polygonDraft {
+		next := g.cursor
+		if i+1 < len(g.polygonDraft) {
+			next = g.polygonDraft[i+1]
+		}
+		vector.StrokeLine(g.world, float32(v.X), float32(v.Y), float32(next.X), float32(next.Y), WALL_WIDTH, WHITE, true)
+	}
+}
+
+func fillPolygon(dst *ebiten.Image, vertices []vec.Vector, c color.RGBA) {
+	var path vector.Path
+	for i, v := range vertices {
+		if i == 0 {
+			path.MoveTo(float32(v.X), float32(v.Y))
+		} else {
+			path.LineTo(float32(v.X), float32(v.Y))
+		}
+	}
+	path.Close()
+
+	op := &vector.DrawPathOptions{AntiAlias: true}
+	op.ColorScale.ScaleWithColor(c)
+	vector.FillPath(dst, &path, nil, op)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/rafibayer/ants-again/vector"
+)
+
+const FOOD_SPACING = 1.5 // default distance between food in a patch
+
+// Scenario describes the starting world.
+// scenarios are loaded from JSON, see scenarios/ for examples.
+type Scenario struct {
+	Hills      []vector.Vector   `json:"hills"`
+	Food       []FoodPatch       `json:"food"`
+	Obstacles  []vector.Vector   `json:"obstacles"`
+	Walls      []WallSpec        `json:"walls"`
+	Polygons   [][]vector.Vector `json:"polygons"`
+	Terrain    *TerrainSpec      `json:"terrain"`
+	Hazards    []HazardSpec      `json:"hazards"`
+	Predators  []vector.Vector   `json:"predators"`
+	Events     []Event           `json:"events"`
+	Script     string            `json:"script"`     // path to a starlark script, relative to the scenario, see script.go
+	Params     json.RawMessage   `json:"params"`     // partial Params as JSON, the base for callers that don't pass their own, see withParams
+	Pheromones []PheromoneKind   `json:"pheromones"` // pheromone types, DefaultPheromones if empty. custom types go after the built in ones
+
+	script *Script
+}
+
+// HazardSpec is a circular hazard zone centered at X, Y.
+type HazardSpec struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"` // at most HAZARD_MAX_RADIUS
+	Lethal bool    `json:"lethal"` // kill ants instead of scaring them away
+}
+
+// FoodPatch is a rectangular grid of food with its top left corner at X, Y.
+type FoodPatch struct {
+	Name    string  `json:"name"` // reported in stats (default: "patch <index>")
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Rows    int     `json:"rows"`
+	Cols    int     `json:"cols"`
+	Spacing float64 `json:"spacing"` // distance between food (default: FOOD_SPACING)
+	Amount  int     `json:"amount"`  // amount per food (default: FOOD_START)
+	Quality float32 `json:"quality"` // food quality (default: 1.0)
+
+	Regrowth float64 `json:"regrowth"` // amount regrown per food per tick, 0 for finite food
+	Capacity int     `json:"capacity"` // max amount per food when regrowing (default: Amount)
+}
+
+type WallSpec struct {
+	A vector.Vector `json:"a"`
+	B vector.Vector `json:"b"`
+}
+
+// Default scenario if nil is passed to NewGame.
+var DefaultScenario = Scenario{
+	Hills: []vector.Vector{{X: GAME_SIZE / 2, Y: GAME_SIZE / 2}},
+	Food: []FoodPatch{
+		{Name: "top left", X: GAME_SIZE / 5, Y: GAME_SIZE / 5, Rows: 10, Cols: 30},
+		{Name: "mid right", X: GAME_SIZE * (5.0 / 6.0), Y: GAME_SIZE / 2, Rows: 10, Cols: 30},
+		{Name: "far bottom right", X: GAME_SIZE * (9.0 / 10.0), Y: GAME_SIZE * (9.0 / 10.0), Rows: 10, Cols: 30},
+	},
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	return loadScenarioWith(os.ReadFile, path)
+}
+
+// loadScenarioWith loads a scenario and the files it references, relative to path, with read.
+// the browser build fetches them over HTTP instead, see browser_js.go.
+func loadScenarioWith(read func(name string) ([]byte, error), path string) (*Scenario, error) {
+	data, err := read(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scenario: %w", err)
+	}
+
+	// reject unknown fields so typos don't silently produce an empty world
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var scenario Scenario
+	if err := dec.Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("error parsing scenario %s: %w", path, err)
+	}
+
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	if scenario.Terrain != nil && scenario.Terrain.Image != "" {
+		name := filepath.Join(filepath.Dir(path), scenario.Terrain.Image)
+		data, err := read(name)
+		if err != nil {
+			return nil, fmt.Errorf("error reading terrain image: %w", err)
+		}
+
+		img, err := decodeTerrainImage(name, data)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Terrain.img = img
+	}
+
+	if scenario.Script != "" {
+		name := filepath.Join(filepath.Dir(path), scenario.Script)
+		src, err := read(name)
+		if err != nil {
+			return nil, fmt.Errorf("error reading script: %w", err)
+		}
+
+		script, err := CompileScript(name, src)
+		if err != nil {
+			return nil, err
+		}
+		scenario.script = script
+	}
+
+	return &scenario, nil
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Hills) == 0 {
+		return errors.New("at least one hill is required")
+	}
+
+	for i, p := range s.Polygons {
+		if len(p) < 3 {
+			return fmt.Errorf("polygon %d has %d vertices, at least 3 are required", i, len(p))
+		}
+	}
+
+	if s.Terrain != nil {
+		for i, r := range s.Terrain.Regions {
+			if r.Speed <= 0 {
+				return fmt.Errorf("terrain region %d has speed %v, must be positive", i, r.Speed)
+			}
+		}
+	}
+
+	for i, h := range s.Hazards {
+		if h.Radius <= 0 || h.Radius > HAZARD_MAX_RADIUS {
+			return fmt.Errorf("hazard %d has radius %v, must be in (0, %v]", i, h.Radius, HAZARD_MAX_RADIUS)
+		}
+	}
+
+	for i := range s.Events {
+		if err := s.Events[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Params != nil {
+		if err := overlayParams(&Params{}, s.Params); err != nil {
+			return err
+		}
+	}
+
+	if err := validPheromones(s.Pheromones); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// withParams returns a copy of base with the scenarios params set on top.
+// callers tuning params apply theirs to the result, so they take precedence.
+func (s *Scenario) withParams(base *Params) *Params {
+	params := base.clone()
+	if s == nil || s.Params == nil {
+		return params
+	}
+
+	// validated when loaded
+	if err := overlayParams(params, s.Params); err != nil {
+		log.Printf("error applying scenario params: %v", err)
+	}
+
+	return params
+}
+
+// loadScenario populates an empty game with the scenarios contents, and spawns the ants.
+func (g *Game) loadScenario(s *Scenario) {
+	for _, hill := range s.Hills {
+		g.hills.Insert(hill)
+	}
+
+	for i, patch := range s.Food {
+		if patch.Name == "" {
+			patch.Name = fmt.Sprintf("patch %d", i)
+		}
+		g.insertFoodPatch(patch)
+	}
+
+	for _, obs := range s.Obstacles {
+		g.obstacles.Insert(&Obstacle{Vector: obs})
+	}
+
+	for _, w := range s.Walls {
+		g.addWall(w.A, w.B)
+	}
+
+	for _, p := range s.Polygons {
+		g.addPolygon(p)
+	}
+
+	if s.Terrain != nil {
+		g.loadTerrain(s.Terrain)
+	}
+
+	for _, h := range s.Hazards {
+		g.addHazard(vector.Vector{X: h.X, Y: h.Y}, h.Radius, h.Lethal)
+	}
+
+	for _, p := range s.Predators {
+		g.addPredator(p)
+	}
+
+	g.scheduleEvents(s.Events)
+
+	if s.script != nil {
+		g.script = newScriptState(s.script)
+	}
+
+	// spread the colony evenly between hills
+	for i := range g.params.AntCount {
+		g.spawnAnt(s.Hills[i%len(s.Hills)])
+	}
+}
+
+func (g *Game) insertFoodPatch(patch FoodPatch) {
+	spacing := patch.Spacing
+	if spacing == 0 {
+		spacing = FOOD_SPACING
+	}
+
+	amount := patch.Amount
+	if amount == 0 {
+		amount = FOOD_START
+	}
+
+	quality := patch.Quality
+	if quality == 0 {
+		quality = 1
+	}
+
+	capacity := patch.Capacity
+	if capacity == 0 {
+		capacity = amount
+	}
+
+	p := &Patch{Name: patch.Name}
+	g.patches = append(g.patches, p)
+
+	for c := range patch.Cols {
+		for r := range patch.Rows {
+			g.food.Insert(&Food{
+				Vector:   &vector.Vector{X: patch.X + float64(c)*spacing, Y: patch.Y + float64(r)*spacing},
+				amount:   amount,
+				capacity: capacity,
+				quality:  quality,
+				regrowth: patch.Regrowth,
+				patch:    p,
+			})
+		}
+	}
+}
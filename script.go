@@ -0,0 +1,336 @@
+// scenario scripts, written in starlark (a python dialect, see https://github.com/bazelbuild/starlark).
+// a script can define any of these hooks, which run on the game loop:
+//
+//	def on_start():        called once before the first tick
+//	def on_tick(tick):     called every tick, after scheduled events
+//	def score():           custom score, returned as a number. reported in stats and used by the gym.
+//
+// and call these builtins:
+//
+//	event(spawn_food = {...})  applies an Event immediately, one action per call, e.g. event(set_params = {"AntSpeed": 2.5})
+//	stats()                    the current stats as a dict, keyed like the recorders columns e.g. stats()["food.collected"]
+//	params()                   the current params as a dict
+//	rand()                     a random float in [0, 1), from the games random source so seeded runs repeat
+//
+// functions can't reassign globals, keep state in a global dict or list instead. see scenarios/seasons.star.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+
+	"github.com/rafibayer/ants-again/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+const SCRIPT_MAX_STEPS = 1_000_000 // starlark steps per hook call, so a runaway loop fails instead of hanging the game
+
+var scriptBuiltins = []string{"event", "stats", "params", "rand"}
+
+// Script is a compiled scenario script, it's shared by every game started from the scenario.
+type Script struct {
+	name    string
+	program *starlark.Program
+}
+
+// CompileScript parses and compiles a script, name is used in errors and log output.
+func CompileScript(name string, src []byte) (*Script, error) {
+	_, program, err := starlark.SourceProgramOptions(&syntax.FileOptions{
+		Set:             true,
+		While:           true,
+		TopLevelControl: true,
+		GlobalReassign:  true,
+	}, name, src, func(name string) bool {
+		return slices.Contains(scriptBuiltins, name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error compiling script: %w", err)
+	}
+
+	return &Script{name: name, program: program}, nil
+}
+
+// scriptState is a script running in one game.
+type scriptState struct {
+	*Script
+	thread *starlark.Thread
+
+	started bool
+	onStart starlark.Callable
+	onTick  starlark.Callable
+	score   starlark.Callable
+}
+
+func newScriptState(script *Script) *scriptState {
+	return &scriptState{
+		Script: script,
+		thread: &starlark.Thread{
+			Name: script.name,
+			Print: func(_ *starlark.Thread, msg string) {
+				log.Printf("%s: %s", script.name, msg)
+			},
+		},
+	}
+}
+
+func (s *scriptState) builtins(g *Game) starlark.StringDict {
+	return starlark.StringDict{
+		"event": starlark.NewBuiltin("event", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if len(args) > 0 {
+				return nil, fmt.Errorf("%s: only keyword arguments are accepted, e.g. event(spawn_food = {...})", b.Name())
+			}
+
+			action := make(map[string]any, len(kwargs))
+			for _, kv := range kwargs {
+				v, err := toGo(kv[1])
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", b.Name(), kv[0], err)
+				}
+				action[string(kv[0].(starlark.String))] = v
+			}
+
+			data, err := json.Marshal(action)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+
+			// reject unknown actions, like scenarios do
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.DisallowUnknownFields()
+
+			var event Event
+			if err := dec.Decode(&event); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			event.Tick = g.tickCount
+
+			if err := event.validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+
+			return starlark.None, g.applyEvent(&event)
+		}),
+		"stats": starlark.NewBuiltin("stats", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+				return nil, err
+			}
+
+			metrics := g.Stats().metrics()
+			dict := starlark.NewDict(len(metrics))
+			for _, m := range metrics {
+				if err := dict.SetKey(starlark.String(m.name), starlark.Float(m.value)); err != nil {
+					return nil, err
+				}
+			}
+			return dict, nil
+		}),
+		"params": starlark.NewBuiltin("params", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+				return nil, err
+			}
+
+			data, err := json.Marshal(g.params)
+			if err != nil {
+				return nil, err
+			}
+
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var params any
+			if err := dec.Decode(&params); err != nil {
+				return nil, err
+			}
+			return fromGo(params), nil
+		}),
+		"rand": starlark.NewBuiltin("rand", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+				return nil, err
+			}
+			return starlark.Float(util.Rand(0, 1)), nil
+		}),
+	}
+}
+
+// call runs a hook with the step limit.
+func (s *scriptState) call(fn starlark.Callable, args ...starlark.Value) (starlark.Value, error) {
+	s.thread.SetMaxExecutionSteps(s.thread.ExecutionSteps() + SCRIPT_MAX_STEPS)
+	v, err := starlark.Call(s.thread, fn, args, nil)
+	if err != nil {
+		return nil, s.wrap(err)
+	}
+
+	return v, nil
+}
+
+// wrap includes the starlark stack in script errors.
+func (s *scriptState) wrap(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return fmt.Errorf("script error: %s", evalErr.Backtrace())
+	}
+
+	return fmt.Errorf("script error in %s: %w", s.name, err)
+}
+
+// start runs the scripts top level and on_start.
+func (s *scriptState) start(g *Game) error {
+	s.started = true
+
+	s.thread.SetMaxExecutionSteps(SCRIPT_MAX_STEPS)
+	globals, err := s.program.Init(s.thread, s.builtins(g))
+	if err != nil {
+		return s.wrap(err)
+	}
+
+	hook := func(name string) (starlark.Callable, error) {
+		v, ok := globals[name]
+		if !ok {
+			return nil, nil
+		}
+
+		fn, ok := v.(starlark.Callable)
+		if !ok {
+			return nil, fmt.Errorf("script error in %s: %s is a %s, not a function", s.name, name, v.Type())
+		}
+		return fn, nil
+	}
+
+	for name, fn := range map[string]*starlark.Callable{"on_start": &s.onStart, "on_tick": &s.onTick, "score": &s.score} {
+		if *fn, err = hook(name); err != nil {
+			return err
+		}
+	}
+
+	if s.onStart != nil {
+		if _, err := s.call(s.onStart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateScript runs the scripts tick hook, starting it on the first tick.
+func (g *Game) updateScript() error {
+	if g.script == nil {
+		return nil
+	}
+
+	if !g.script.started {
+		if err := g.script.start(g); err != nil {
+			return err
+		}
+	}
+
+	if g.script.onTick != nil {
+		if _, err := g.script.call(g.script.onTick, starlark.MakeInt(g.tickCount)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scoreScript updates the custom score, if the script defines one.
+func (g *Game) scoreScript() error {
+	if g.script == nil || g.script.score == nil {
+		return nil
+	}
+
+	v, err := g.script.call(g.script.score)
+	if err != nil {
+		return err
+	}
+
+	score, ok := starlark.AsFloat(v)
+	if !ok {
+		return fmt.Errorf("script error in %s: score returned a %s, not a number", g.script.name, v.Type())
+	}
+
+	g.scriptScore = score
+	g.scored = true
+	return nil
+}
+
+// toGo converts starlark values to their JSON equivalent.
+func toGo(v starlark.Value) (any, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("int %s out of range", v)
+		}
+		return i, nil
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case *starlark.Dict:
+		m := make(map[string]any, v.Len())
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, got %s", item[0].Type())
+			}
+
+			value, err := toGo(item[1])
+			if err != nil {
+				return nil, err
+			}
+			m[string(key)] = value
+		}
+		return m, nil
+	case starlark.Indexable:
+		list := make([]any, v.Len())
+		for i := range list {
+			value, err := toGo(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("can't convert %s", v.Type())
+}
+
+// fromGo converts JSON values, decoded with UseNumber, to starlark.
+func fromGo(v any) starlark.Value {
+	switch v := v.(type) {
+	case bool:
+		return starlark.Bool(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return starlark.MakeInt64(i)
+		}
+		f, _ := v.Float64()
+		return starlark.Float(f)
+	case string:
+		return starlark.String(v)
+	case map[string]any:
+		dict := starlark.NewDict(len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			dict.SetKey(starlark.String(key), fromGo(v[key]))
+		}
+		return dict
+	case []any:
+		list := make([]starlark.Value, len(v))
+		for i := range v {
+			list[i] = fromGo(v[i])
+		}
+		return starlark.NewList(list)
+	}
+
+	return starlark.None
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func scriptGame(t *testing.T, src string) *Game {
+	script, err := CompileScript("test.star", []byte(src))
+	require.NoError(t, err)
+
+	return newTestGame(Scenario{script: script}, func(p *Params) {
+		p.AntCount = 1
+	})
+}
+
+func TestScriptHooks(t *testing.T) {
+	game := scriptGame(t, `
+ticks = []
+
+def on_start():
+    event(set_params = {"AntSpeed": params()["AntSpeed"] * 2})
+
+def on_tick(tick):
+    ticks.append(tick)
+    if tick == 1:
+        event(spawn_food = {"name": "scripted", "x": 100, "y": 100, "rows": 2, "cols": 3})
+
+def score():
+    return len(ticks) + stats()["food.left"]
+`)
+
+	for range 3 {
+		require.NoError(t, game.Update())
+	}
+
+	require.Equal(t, DefaultParams.AntSpeed*2, game.params.AntSpeed)
+	require.Len(t, game.patches, 1)
+	require.Equal(t, "scripted", game.patches[0].Name)
+
+	st := game.Stats()
+	require.True(t, st.scored)
+	require.Equal(t, float64(3+6*FOOD_START), st.score)
+	require.Contains(t, st.metrics(), metric{"score", st.score})
+}
+
+func TestScriptErrors(t *testing.T) {
+	_, err := CompileScript("test.star", []byte("def on_tick(:"))
+	require.ErrorContains(t, err, "error compiling script")
+
+	_, err = CompileScript("test.star", []byte("undefined()"))
+	require.Error(t, err)
+
+	cases := map[string]string{
+		"runtime":       "def on_tick(tick):\n    fail('boom')",
+		"unknown event": "def on_tick(tick):\n    event(spawn_ants = {})",
+		"two actions":   "def on_tick(tick):\n    event(add_obstacle = {'x': 1, 'y': 1}, add_hill = {'x': 1, 'y': 1})",
+		"bad params":    "def on_start():\n    event(set_params = {'Nope': 1})",
+		"runaway":       "def on_tick(tick):\n    while True:\n        pass",
+		"score type":    "def score():\n    return 'high'",
+		"hook type":     "on_tick = 1",
+	}
+
+	for name, src := range cases {
+		game := scriptGame(t, src)
+		require.Error(t, game.Update(), name)
+	}
+}
+
+func TestScriptDeterministic(t *testing.T) {
+	run := func() []vector.Vector {
+		util.Seed(1)
+		game := scriptGame(t, `
+def on_tick(tick):
+    event(spawn_food = {"x": rand() * 1000, "y": rand() * 1000, "rows": 1, "cols": 1})
+`)
+		for range 5 {
+			require.NoError(t, game.Update())
+		}
+
+		food := []vector.Vector{}
+		for f := range game.food.PointsIter() {
+			food = append(food, *f.Vector)
+		}
+		return food
+	}
+
+	first := run()
+	require.Len(t, first, 5)
+	require.ElementsMatch(t, first, run())
+}
+
+func TestLoadScenarioWith(t *testing.T) {
+	// like the browser build, which fetches files relative to the page
+	files := fstest.MapFS{
+		"levels/one.json":           {Data: []byte(`{"hills": [{"x": 500, "y": 500}], "script": "scripts/score.star"}`)},
+		"levels/scripts/score.star": {Data: []byte("def score():\n    return 0.5\n")},
+	}
+
+	scenario, err := loadScenarioWith(files.ReadFile, "levels/one.json")
+	require.NoError(t, err)
+
+	game := NewGame(nil, scenario)
+	require.NoError(t, game.tick())
+	require.Equal(t, 0.5, game.Stats().score)
+
+	_, err = loadScenarioWith(files.ReadFile, "levels/two.json")
+	require.ErrorContains(t, err, "error reading scenario")
+}
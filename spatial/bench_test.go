@@ -0,0 +1,113 @@
+package spatial_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/rafibayer/ants-again/spatial"
+	vec "github.com/rafibayer/ants-again/vector"
+)
+
+const (
+	benchWorld    = 1000.0
+	benchCellSize = benchWorld / 20.0
+)
+
+var (
+	benchDensities = []int{1_000, 10_000, 100_000}
+	benchRadii     = []float64{benchWorld / 100, benchWorld / 10, benchWorld / 4}
+)
+
+type benchImpl struct {
+	name string
+	new  func() spatial.Spatial[*vec.Vector]
+}
+
+var benchImpls = []benchImpl{
+	{"hash", func() spatial.Spatial[*vec.Vector] { return spatial.NewHash[*vec.Vector](benchCellSize) }},
+	{"grid", func() spatial.Spatial[*vec.Vector] {
+		return spatial.NewGrid[*vec.Vector](benchCellSize, benchWorld, benchWorld)
+	}},
+}
+
+func benchPoints(n int) []*vec.Vector {
+	r := rand.New(rand.NewPCG(1, 2))
+	points := make([]*vec.Vector, n)
+	for i := range points {
+		points[i] = &vec.Vector{X: r.Float64() * benchWorld, Y: r.Float64() * benchWorld}
+	}
+
+	return points
+}
+
+func filled(impl benchImpl, points []*vec.Vector) spatial.Spatial[*vec.Vector] {
+	sp := impl.new()
+	for _, p := range points {
+		sp.Insert(p)
+	}
+
+	return sp
+}
+
+func BenchmarkInsert(b *testing.B) {
+	for _, impl := range benchImpls {
+		for _, n := range benchDensities {
+			points := benchPoints(n + 1024)
+			points, extra := points[:n], points[n:]
+			b.Run(fmt.Sprintf("%s/n=%d", impl.name, n), func(b *testing.B) {
+				// insert into a grid already at the density, construction isn't timed.
+				// each new point is removed again so the density stays at n
+				sp := filled(impl, points)
+				b.ResetTimer()
+
+				i := 0
+				for b.Loop() {
+					p := extra[i%len(extra)]
+					sp.Insert(p)
+					sp.Remove(p)
+					i++
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRemove(b *testing.B) {
+	for _, impl := range benchImpls {
+		for _, n := range benchDensities {
+			points := benchPoints(n)
+			b.Run(fmt.Sprintf("%s/n=%d", impl.name, n), func(b *testing.B) {
+				sp := filled(impl, points)
+				i := 0
+				for b.Loop() {
+					// remove and reinsert to keep the density constant
+					p := points[i%len(points)]
+					sp.Remove(p)
+					sp.Insert(p)
+					i++
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRadialSearchIter(b *testing.B) {
+	for _, impl := range benchImpls {
+		for _, n := range benchDensities {
+			points := benchPoints(n)
+			centers := benchPoints(1024)
+			for _, radius := range benchRadii {
+				b.Run(fmt.Sprintf("%s/n=%d/r=%.0f", impl.name, n, radius), func(b *testing.B) {
+					sp := filled(impl, points)
+					i := 0
+					for b.Loop() {
+						for range sp.RadialSearchIter(centers[i%len(centers)], radius) {
+						}
+						i++
+					}
+				})
+			}
+		}
+	}
+}
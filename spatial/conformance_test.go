@@ -0,0 +1,288 @@
+package spatial_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/rafibayer/ants-again/spatial"
+	vec "github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+// conformance suite shared by every spatial.Spatial implementation.
+// each implementation is compared against a brute-force reference.
+
+const (
+	confCellSize = 10.0
+	confWorld    = 100.0
+)
+
+var implementations = []struct {
+	name string
+	new  func() spatial.Spatial[vec.Vector]
+}{
+	{"hash", func() spatial.Spatial[vec.Vector] { return spatial.NewHash[vec.Vector](confCellSize) }},
+	{"grid", func() spatial.Spatial[vec.Vector] {
+		return spatial.NewGrid[vec.Vector](confCellSize, confWorld, confWorld)
+	}},
+}
+
+// reference is a brute-force spatial structure.
+type reference []vec.Vector
+
+func (r *reference) insert(p vec.Vector) {
+	*r = append(*r, p)
+}
+
+func (r *reference) remove(p vec.Vector) bool {
+	i := slices.Index(*r, p)
+	if i == -1 {
+		return false
+	}
+
+	*r = slices.Delete(*r, i, i+1)
+	return true
+}
+
+func (r reference) radialSearch(center vec.Vector, radius float64) []vec.Vector {
+	result := []vec.Vector{}
+	for _, p := range r {
+		xDiff := center.X - p.X
+		yDiff := center.Y - p.Y
+		if (xDiff*xDiff + yDiff*yDiff) <= radius*radius {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
+// checker applies operations to an implementation and the reference in lockstep.
+type checker struct {
+	t   testing.TB
+	sp  spatial.Spatial[vec.Vector]
+	ref reference
+}
+
+func (c *checker) insert(p vec.Vector) {
+	c.sp.Insert(p)
+	c.ref.insert(p)
+	c.checkLen()
+}
+
+func (c *checker) remove(p vec.Vector) {
+	removed := c.sp.Remove(p)
+	if c.ref.remove(p) {
+		require.Equal(c.t, p, removed, "remove %v", p)
+	} else {
+		require.Zero(c.t, removed, "remove missing %v", p)
+	}
+	c.checkLen()
+}
+
+func (c *checker) checkLen() {
+	require.Equal(c.t, len(c.ref), c.sp.Len())
+}
+
+func (c *checker) checkPoints() {
+	require.ElementsMatch(c.t, []vec.Vector(c.ref), c.sp.Points())
+	require.ElementsMatch(c.t, []vec.Vector(c.ref), slices.Collect(c.sp.PointsIter()))
+}
+
+func (c *checker) checkSearch(center vec.Vector, radius float64) {
+	want := c.ref.radialSearch(center, radius)
+	require.ElementsMatch(c.t, want, c.sp.RadialSearch(center, radius), "search %v r=%v", center, radius)
+	require.ElementsMatch(c.t, want, slices.Collect(c.sp.RadialSearchIter(center, radius)), "search iter %v r=%v", center, radius)
+}
+
+func forEachImpl(t *testing.T, test func(t *testing.T, c *checker)) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			test(t, &checker{t: t, sp: impl.new()})
+		})
+	}
+}
+
+func TestConformanceEmpty(t *testing.T) {
+	forEachImpl(t, func(t *testing.T, c *checker) {
+		c.checkLen()
+		c.checkPoints()
+		c.checkSearch(vec.Vector{X: 50, Y: 50}, 1000)
+		c.remove(vec.Vector{X: 1, Y: 1})
+	})
+}
+
+func TestConformanceRandom(t *testing.T) {
+	forEachImpl(t, func(t *testing.T, c *checker) {
+		r := rand.New(rand.NewPCG(1, 2))
+		randPoint := func() vec.Vector {
+			// extend past the world on every side to cover negative and out of bounds coordinates
+			return vec.Vector{X: r.Float64()*2*confWorld - confWorld/2, Y: r.Float64()*2*confWorld - confWorld/2}
+		}
+
+		for range 2000 {
+			switch {
+			case len(c.ref) > 0 && r.IntN(3) == 0:
+				c.remove(c.ref[r.IntN(len(c.ref))])
+			case r.IntN(10) == 0:
+				c.remove(randPoint())
+			default:
+				c.insert(randPoint())
+			}
+
+			if r.IntN(20) == 0 {
+				c.checkSearch(randPoint(), r.Float64()*confWorld/2)
+			}
+		}
+
+		c.checkPoints()
+	})
+}
+
+func TestConformanceDuplicates(t *testing.T) {
+	forEachImpl(t, func(t *testing.T, c *checker) {
+		p := vec.Vector{X: 5, Y: 5}
+		c.insert(p)
+		c.insert(p)
+		c.checkSearch(p, 0)
+
+		c.remove(p)
+		c.checkPoints()
+		c.remove(p)
+		c.remove(p)
+		c.checkPoints()
+	})
+}
+
+func TestConformanceNegative(t *testing.T) {
+	forEachImpl(t, func(t *testing.T, c *checker) {
+		points := []vec.Vector{{X: -1, Y: -1}, {X: -0.5, Y: 0.5}, {X: -15, Y: 3}, {X: -25, Y: -25}}
+		for _, p := range points {
+			c.insert(p)
+		}
+
+		c.checkSearch(vec.Vector{X: 0, Y: 0}, 1)
+		c.checkSearch(vec.Vector{X: 0, Y: 0}, 1.5)
+		c.checkSearch(vec.Vector{X: -10, Y: 0}, 6)
+		c.checkSearch(vec.Vector{X: -20, Y: -20}, 8)
+		c.checkSearch(vec.Vector{X: 5, Y: 5}, 50)
+
+		for _, p := range points {
+			c.remove(p)
+		}
+		c.checkPoints()
+	})
+}
+
+func TestConformanceCellBoundaries(t *testing.T) {
+	forEachImpl(t, func(t *testing.T, c *checker) {
+		// points exactly on cell edges and corners
+		for x := -confCellSize; x <= confWorld+confCellSize; x += confCellSize {
+			for y := -confCellSize; y <= confWorld+confCellSize; y += confCellSize {
+				c.insert(vec.Vector{X: x, Y: y})
+			}
+		}
+
+		// radii that land exactly on neighbouring cell edges, and centers on edges
+		for _, center := range []vec.Vector{{X: 50, Y: 50}, {X: 45, Y: 45}, {X: 0, Y: 0}, {X: confWorld, Y: confWorld}, {X: 49.999, Y: 50}} {
+			for _, radius := range []float64{0, confCellSize / 2, confCellSize, 2 * confCellSize, confCellSize * 1.4142} {
+				c.checkSearch(center, radius)
+			}
+		}
+
+		c.remove(vec.Vector{X: 0, Y: 0})
+		c.remove(vec.Vector{X: confWorld, Y: confWorld})
+		c.checkPoints()
+	})
+}
+
+func TestConformanceUnboundedRadius(t *testing.T) {
+	forEachImpl(t, func(t *testing.T, c *checker) {
+		// spread over every column and row, including out of bounds
+		for x := -confCellSize; x <= confWorld+confCellSize; x += confCellSize / 2 {
+			c.insert(vec.Vector{X: x, Y: confWorld - x})
+		}
+
+		for _, center := range []vec.Vector{{X: 50, Y: 50}, {X: -1e300, Y: 1e300}, {X: 0, Y: 0}} {
+			c.checkSearch(center, math.Inf(1))
+			c.checkSearch(center, math.MaxFloat64)
+		}
+		require.Len(t, c.sp.RadialSearch(vec.Vector{X: 50, Y: 50}, math.Inf(1)), len(c.ref))
+	})
+}
+
+// runOps decodes a byte string into a sequence of operations, 4 bytes each:
+// an opcode, two signed coordinates in half-cell steps, and a radius.
+func runOps(t *testing.T, newSpatial func() spatial.Spatial[vec.Vector], ops []byte) {
+	c := &checker{t: t, sp: newSpatial()}
+
+	for len(ops) >= 4 {
+		op, p := ops[0], vec.Vector{
+			X: float64(int8(ops[1])) * confCellSize / 2,
+			Y: float64(int8(ops[2])) * confCellSize / 2,
+		}
+		radius := float64(ops[3]) / 2
+		ops = ops[4:]
+
+		switch op % 3 {
+		case 0:
+			c.insert(p)
+		case 1:
+			c.remove(p)
+		case 2:
+			c.checkSearch(p, radius)
+		}
+	}
+
+	c.checkPoints()
+}
+
+func fuzzSeeds(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 1, 0, 2, 1, 1, 4})
+	f.Add([]byte{0, 0xff, 0xff, 0, 0, 2, 2, 0, 2, 0, 0, 20, 1, 0xff, 0xff, 0, 2, 0, 0, 20})
+	f.Add([]byte{0, 20, 20, 0, 0, 20, 20, 0, 1, 20, 20, 0, 2, 19, 19, 30, 1, 20, 20, 0})
+}
+
+func FuzzHash(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		runOps(t, implementations[0].new, ops)
+	})
+}
+
+func FuzzGrid(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		runOps(t, implementations[1].new, ops)
+	})
+}
+
+func TestConformanceRemoveFunc(t *testing.T) {
+	impls := map[string]spatial.Spatial[*vec.Vector]{
+		"hash": spatial.NewHash[*vec.Vector](confCellSize),
+		"grid": spatial.NewGrid[*vec.Vector](confCellSize, confWorld, confWorld),
+	}
+
+	for name, sp := range impls {
+		t.Run(name, func(t *testing.T) {
+			a, b := &vec.Vector{X: 5, Y: 5}, &vec.Vector{X: 5, Y: 5}
+			sp.Insert(a)
+			sp.Insert(b)
+
+			// same position, different point
+			same := func(p *vec.Vector) func(*vec.Vector) bool {
+				return func(other *vec.Vector) bool { return other == p }
+			}
+			require.Same(t, b, sp.RemoveFunc(b, same(b)))
+			require.Nil(t, sp.RemoveFunc(b, same(b)))
+			require.Equal(t, []*vec.Vector{a}, sp.Points())
+
+			require.Nil(t, sp.RemoveFunc(&vec.Vector{X: 6, Y: 5}, same(a)))
+			require.Same(t, a, sp.RemoveFunc(a, same(a)))
+			require.Zero(t, sp.Len())
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package spatial
+
+import (
+	"iter"
+	"math"
+
+	"github.com/rafibayer/ants-again/vector"
+)
+
+// Grid is a dense alternative to Hash for bounded worlds.
+// cells are stored in a flat slice indexed by y*cols+x, so probing a cell
+// is an index instead of a map lookup, and iteration order is deterministic.
+// points outside of the bounds are clamped into the edge cells.
+type Grid[T vector.Point] struct {
+	len        int
+	size       float64
+	cols, rows int
+	cells      [][]T
+}
+
+var _ Spatial[vector.Point] = &Grid[vector.Point]{}
+
+// NewGrid creates a grid of cells with the given size covering [0, width) x [0, height).
+func NewGrid[T vector.Point](size, width, height float64) Spatial[T] {
+	cols := max(1, int(math.Ceil(width/size)))
+	rows := max(1, int(math.Ceil(height/size)))
+
+	return &Grid[T]{
+		size:  size,
+		cols:  cols,
+		rows:  rows,
+		cells: make([][]T, cols*rows),
+	}
+}
+
+func (g *Grid[T]) col(x float64) int {
+	return clampCell(x/g.size, g.cols)
+}
+
+func (g *Grid[T]) row(y float64) int {
+	return clampCell(y/g.size, g.rows)
+}
+
+func (g *Grid[T]) index(p vector.Point) int {
+	return g.row(p.GetY())*g.cols + g.col(p.GetX())
+}
+
+func (g *Grid[T]) Insert(p T) {
+	i := g.index(p)
+	g.cells[i] = append(g.cells[i], p)
+	g.len++
+}
+
+func (g *Grid[T]) Points() []T {
+	result := make([]T, 0, g.Len())
+	for _, cell := range g.cells {
+		result = append(result, cell...)
+	}
+
+	return result
+}
+
+func (g *Grid[T]) PointsIter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, cell := range g.cells {
+			for _, p := range cell {
+				if !yield(p) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (g *Grid[T]) RadialSearch(center vector.Point, radius float64) []T {
+	result := []T{}
+	for p := range g.RadialSearchIter(center, radius) {
+		result = append(result, p)
+	}
+
+	return result
+}
+
+func (g *Grid[T]) RadialSearchIter(center vector.Point, radius float64) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		cx, cy := center.GetX(), center.GetY()
+		r2 := radius * radius
+
+		// candidate cells, clamped to the grid.
+		// out of bounds points live in the edge cells, so clamping the range still finds them.
+		x0, x1 := g.col(cx-radius), g.col(cx+radius)
+		y0, y1 := g.row(cy-radius), g.row(cy+radius)
+
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				points := g.cells[y*g.cols+x]
+				if len(points) == 0 {
+					continue
+				}
+
+				// Cell-Circle Intersection Test, see Hash.RadialSearchIter.
+				// edge cells extend to infinity since they hold clamped points.
+				minX, maxX := g.cellBounds(x, g.cols)
+				minY, maxY := g.cellBounds(y, g.rows)
+
+				closestX := math.Max(minX, math.Min(cx, maxX))
+				closestY := math.Max(minY, math.Min(cy, maxY))
+
+				distX := cx - closestX
+				distY := cy - closestY
+				if (distX*distX)+(distY*distY) > r2 {
+					continue
+				}
+
+				for _, p := range points {
+					xDiff := cx - p.GetX()
+					yDiff := cy - p.GetY()
+					if (xDiff*xDiff + yDiff*yDiff) <= r2 {
+						if !yield(p) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// cellBounds returns the extent of cell i along an axis with n cells.
+func (g *Grid[T]) cellBounds(i, n int) (float64, float64) {
+	low := float64(i) * g.size
+	high := low + g.size
+	if i == 0 {
+		low = math.Inf(-1)
+	}
+	if i == n-1 {
+		high = math.Inf(1)
+	}
+
+	return low, high
+}
+
+func (g *Grid[T]) Remove(p T) T {
+	return g.RemoveFunc(p, func(T) bool { return true })
+}
+
+func (g *Grid[T]) RemoveFunc(p T, match func(T) bool) T {
+	i := g.index(p)
+	cell := g.cells[i]
+
+	index := -1
+	for j, c := range cell {
+		if c.GetX() == p.GetX() && c.GetY() == p.GetY() && match(c) {
+			index = j
+			break
+		}
+	}
+
+	if index == -1 {
+		var zero T
+		return zero
+	}
+
+	g.len--
+
+	// swap with last and truncate
+	deleted := cell[index]
+	cell[index] = cell[len(cell)-1]
+	g.cells[i] = cell[:len(cell)-1]
+
+	return deleted
+}
+
+func (g *Grid[T]) Len() int {
+	return g.len
+}
+
+// clampCell returns the cell containing coordinate c (in cells) along an axis with n cells.
+// clamping happens before converting, since converting infinite or huge floats to int is implementation-defined.
+func clampCell(c float64, n int) int {
+	return int(min(max(math.Floor(c), 0), float64(n-1)))
+}
@@ -0,0 +1,55 @@
+package spatial_test
+
+import (
+	"testing"
+
+	"github.com/rafibayer/ants-again/spatial"
+	vec "github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrid(t *testing.T) {
+	sp := spatial.NewGrid[vec.Vector](1.0, 10, 10)
+	sp.Insert(vec.Vector{X: 5, Y: 5})
+
+	p := sp.Points()
+	require.Len(t, p, 1)
+	require.Equal(t, vec.Vector{X: 5, Y: 5}, p[0])
+
+	sp.Remove(vec.Vector{X: 4, Y: 4})
+	p = sp.Points()
+	require.Len(t, p, 1)
+	require.Equal(t, vec.Vector{X: 5, Y: 5}, p[0])
+
+	r := sp.Remove(vec.Vector{X: 5, Y: 5})
+	require.Equal(t, vec.Vector{X: 5, Y: 5}, r)
+	p = sp.Points()
+	require.Len(t, p, 0)
+}
+
+func TestGridOutOfBounds(t *testing.T) {
+	sp := spatial.NewGrid[vec.Vector](1.0, 10, 10)
+	sp.Insert(vec.Vector{X: -3, Y: 4})
+	sp.Insert(vec.Vector{X: 12, Y: 12})
+
+	require.Equal(t, []vec.Vector{{X: -3, Y: 4}}, sp.RadialSearch(vec.Vector{X: -3.5, Y: 4}, 1))
+	require.Equal(t, []vec.Vector{{X: 12, Y: 12}}, sp.RadialSearch(vec.Vector{X: 9, Y: 9}, 5))
+	require.Empty(t, sp.RadialSearch(vec.Vector{X: 5, Y: 5}, 2))
+
+	require.Equal(t, vec.Vector{X: -3, Y: 4}, sp.Remove(vec.Vector{X: -3, Y: 4}))
+	require.Equal(t, 1, sp.Len())
+}
+
+func TestGridDeterministicOrder(t *testing.T) {
+	points := []vec.Vector{{X: 9, Y: 9}, {X: 1, Y: 1}, {X: 5, Y: 2}, {X: 2, Y: 5}}
+
+	a := spatial.NewGrid[vec.Vector](1.0, 10, 10)
+	b := spatial.NewGrid[vec.Vector](1.0, 10, 10)
+	for _, p := range points {
+		a.Insert(p)
+		b.Insert(p)
+	}
+
+	require.Equal(t, a.Points(), b.Points())
+	require.Equal(t, []vec.Vector{{X: 1, Y: 1}, {X: 5, Y: 2}, {X: 2, Y: 5}, {X: 9, Y: 9}}, a.Points())
+}
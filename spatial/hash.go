@@ -40,7 +40,7 @@ func (h *Hash[T]) Insert(p T) {
 }
 
 func (h *Hash[T]) Points() []T {
-	result := make([]T, h.Len())
+	result := make([]T, 0, h.Len())
 	for _, cell := range h.cells {
 		result = append(result, cell...)
 	}
@@ -76,54 +76,73 @@ func (h *Hash[T]) RadialSearchIter(center vector.Point, radius float64) iter.Seq
 		cx := int(math.Floor(center.GetX() / h.size))
 		cy := int(math.Floor(center.GetY() / h.size))
 
-		// ceil(radius / cell_size)
-		cellRadius := int(math.Ceil(radius / h.size))
 		r2 := radius * radius
 
-		// 2. Iterate the square grid of candidates
-		for dx := -cellRadius; dx <= cellRadius; dx++ {
-			for dy := -cellRadius; dy <= cellRadius; dy++ {
-				key := hashKey{x: cx + dx, y: cy + dy}
+		// visit yields the points of a cell within the radius, returning false if iteration stopped.
+		visit := func(key hashKey, points []T) bool {
+			// Optimization 1: If the cell is empty, we don't care if it overlaps.
+			if len(points) == 0 {
+				return true
+			}
 
-				// Optimization 1: Check map existence first.
-				// If the cell is empty, we don't care if it overlaps.
-				points, ok := h.cells[key]
-				if !ok || len(points) == 0 {
-					continue
+			// Optimization 2: Cell-Circle Intersection Test
+			// We find the closest point on the grid cell to the search center.
+			// Calculate cell bounds
+			minX := float64(key.x) * h.size
+			maxX := minX + h.size
+			minY := float64(key.y) * h.size
+			maxY := minY + h.size
+
+			// Clamp the center to the cell bounds to find the closest point
+			closestX := math.Max(minX, math.Min(center.GetX(), maxX))
+			closestY := math.Max(minY, math.Min(center.GetY(), maxY))
+
+			// Calculate squared distance from center to that closest point
+			distX := center.GetX() - closestX
+			distY := center.GetY() - closestY
+			distSq := (distX * distX) + (distY * distY)
+
+			// If the closest point on the square is outside the radius,
+			// the whole square is outside.
+			if distSq > r2 {
+				return true
+			}
+
+			// 3. Point-Circle Intersection Test (Standard)
+			for _, p := range points {
+				xDiff := center.GetX() - p.GetX()
+				yDiff := center.GetY() - p.GetY()
+				if (xDiff*xDiff + yDiff*yDiff) <= r2 {
+					if !yield(p) {
+						return false
+					}
 				}
+			}
 
-				// Optimization 2: Cell-Circle Intersection Test
-				// We find the closest point on the grid cell to the search center.
-				// Calculate cell bounds
-				minX := float64(key.x) * h.size
-				maxX := minX + h.size
-				minY := float64(key.y) * h.size
-				maxY := minY + h.size
-
-				// Clamp the center to the cell bounds to find the closest point
-				closestX := math.Max(minX, math.Min(center.GetX(), maxX))
-				closestY := math.Max(minY, math.Min(center.GetY(), maxY))
-
-				// Calculate squared distance from center to that closest point
-				distX := center.GetX() - closestX
-				distY := center.GetY() - closestY
-				distSq := (distX * distX) + (distY * distY)
-
-				// If the closest point on the square is outside the radius,
-				// the whole square is outside.
-				if distSq > r2 {
-					continue
+			return true
+		}
+
+		// unbounded or huge radii cover more cells than are occupied, scan those instead.
+		// this also avoids converting an infinite cell radius to int.
+		span := 2*math.Ceil(radius/h.size) + 1
+		if span*span > float64(len(h.cells)) {
+			for key, points := range h.cells {
+				if !visit(key, points) {
+					return
 				}
+			}
+			return
+		}
 
-				// 3. Point-Circle Intersection Test (Standard)
-				for _, p := range points {
-					xDiff := center.GetX() - p.GetX()
-					yDiff := center.GetY() - p.GetY()
-					if (xDiff*xDiff + yDiff*yDiff) <= r2 {
-						if !yield(p) {
-							return
-						}
-					}
+		// ceil(radius / cell_size)
+		cellRadius := int(math.Ceil(radius / h.size))
+
+		// 2. Iterate the square grid of candidates
+		for dx := -cellRadius; dx <= cellRadius; dx++ {
+			for dy := -cellRadius; dy <= cellRadius; dy++ {
+				key := hashKey{x: cx + dx, y: cy + dy}
+				if !visit(key, h.cells[key]) {
+					return
 				}
 			}
 		}
@@ -132,11 +151,15 @@ func (h *Hash[T]) RadialSearchIter(center vector.Point, radius float64) iter.Seq
 }
 
 func (h *Hash[T]) Remove(p T) T {
+	return h.RemoveFunc(p, func(T) bool { return true })
+}
+
+func (h *Hash[T]) RemoveFunc(p T, match func(T) bool) T {
 	k := h.key(p)
 
 	index := -1
 	for i, c := range h.cells[k] {
-		if c.GetX() == p.GetX() && c.GetY() == p.GetY() {
+		if c.GetX() == p.GetX() && c.GetY() == p.GetY() && match(c) {
 			index = i
 			break
 		}
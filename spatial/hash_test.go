@@ -26,3 +26,22 @@ func TestHash(t *testing.T) {
 	p = sp.Points()
 	require.Len(t, p, 0)
 }
+
+func TestHashLargeRadius(t *testing.T) {
+	// searches covering more cells than are occupied scan the occupied cells instead
+	sp := spatial.NewHash[vec.Vector](1.0)
+	sp.Insert(vec.Vector{X: 0, Y: 0})
+	sp.Insert(vec.Vector{X: 1e6, Y: 0})
+	sp.Insert(vec.Vector{X: -1e6, Y: -1e6})
+
+	require.ElementsMatch(t, []vec.Vector{{X: 0, Y: 0}, {X: 1e6, Y: 0}}, sp.RadialSearch(vec.Vector{X: 0, Y: 0}, 1e6))
+	require.Len(t, sp.RadialSearch(vec.Vector{X: 0, Y: 0}, 2e6), 3)
+
+	// stopping early stops the scan
+	n := 0
+	for range sp.RadialSearchIter(vec.Vector{X: 0, Y: 0}, 2e6) {
+		n++
+		break
+	}
+	require.Equal(t, 1, n)
+}
@@ -12,7 +12,17 @@ type Spatial[T vector.Point] interface {
 	Points() []T
 	PointsIter() iter.Seq[T]
 	Remove(p T) T
+	RemoveFunc(p T, match func(T) bool) T // removes the first point at p's position that matches, e.g. p itself
 	RadialSearch(center vector.Point, radius float64) []T
 	RadialSearchIter(center vector.Point, radius float64) iter.Seq[T]
 	Len() int
 }
+
+// RemoveSame removes p itself, not just any point at its position.
+// points are often pointers, and several can share a position.
+func RemoveSame[T interface {
+	vector.Point
+	comparable
+}](s Spatial[T], p T) T {
+	return s.RemoveFunc(p, func(other T) bool { return other == p })
+}
@@ -12,44 +12,95 @@ type Stats struct {
 	tps   string
 
 	ants struct {
+		alive     int
 		foraging  int
 		returning int
+		born      int
+		died      int
+		killed    int
 	}
 	food struct {
 		left      int
 		collected int
+		stored    int
 	}
-	pheromone struct {
-		forage   int
-		returing int
-	}
+	pheromones []pheromoneStats
+	score      float64 // custom score from the scenario script, if scored
+	scored     bool
+	castes     []casteStats
+	patches    []patchStats
+}
+
+type patchStats struct {
+	name      string
+	left      int
+	collected int
+}
+
+type pheromoneStats struct {
+	name  string
+	marks int
+}
+
+type casteStats struct {
+	name      string
+	ants      int
+	collected int
 }
 
 func (g *Game) Stats() *Stats {
+	castes := make([]casteStats, len(g.castes()))
+	for i, c := range g.castes() {
+		castes[i] = casteStats{name: c.Name, collected: g.casteCollected[i]}
+	}
+	for _, ant := range g.ants {
+		if ant.caste < len(castes) {
+			castes[ant.caste].ants++
+		}
+	}
+
+	patches := make([]patchStats, len(g.patches))
+	for i, p := range g.patches {
+		patches[i] = patchStats{name: p.Name, left: p.left, collected: p.collected}
+	}
+
+	pheromones := make([]pheromoneStats, len(g.pheromones))
+	for kind, field := range g.pheromones {
+		pheromones[kind] = pheromoneStats{name: g.pheromoneTypes[kind].Name, marks: field.Len()}
+	}
+
 	return &Stats{
 		ticks: g.tickCount,
 		fps:   fmt.Sprintf("%.0f", ebiten.ActualFPS()),
 		tps:   fmt.Sprintf("%.0f", ebiten.ActualTPS()),
 		ants: struct {
+			alive     int
 			foraging  int
 			returning int
+			born      int
+			died      int
+			killed    int
 		}{
+			alive:     len(g.ants),
 			foraging:  g.foragingAntCount,
 			returning: g.returningAntCount,
+			born:      g.bornAntCount,
+			died:      g.deadAntCount,
+			killed:    g.killedAntCount,
 		},
 		food: struct {
 			left      int
 			collected int
+			stored    int
 		}{
 			left:      g.remainingFoodCount,
 			collected: g.collectedFood,
+			stored:    g.storedFood,
 		},
-		pheromone: struct {
-			forage   int
-			returing int
-		}{
-			forage:   g.foragingPheromone.Len(),
-			returing: g.returningPheromone.Len(),
-		},
+		pheromones: pheromones,
+		score:      g.scriptScore,
+		scored:     g.scored,
+		castes:     castes,
+		patches:    patches,
 	}
 }
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	STREAM_EVERY           = TPS / 10 // default ticks between frames
+	STREAM_WORLD_EVERY     = TPS      // ticks between resending hills, walls and the rest of the static world
+	STREAM_GRID            = 50       // pheromone summary cells per side
+	STREAM_PHEROMONE_SCALE = 16.0     // summed pheromone per cell is multiplied by this and clamped to a byte
+	STREAM_CLIENT_BUFFER   = 4        // frames queued per viewer before frames are dropped
+	STREAM_WRITE_TIMEOUT   = 5 * time.Second
+)
+
+//go:embed viewer.html
+var viewerHTML []byte
+
+// Streamer broadcasts compact frames of the world to websocket viewers.
+// frames are built from the same game state the draw functions in render.go read.
+type Streamer struct {
+	every int
+
+	mu      sync.Mutex
+	clients map[*streamClient]bool
+
+	food      map[*Food]int // amounts sent in the last frame, for food deltas
+	lastWorld int           // tick the world was last sent
+}
+
+type streamClient struct {
+	frames   chan []byte
+	keyframe bool // the next frame must be a keyframe, guarded by Streamer.mu
+}
+
+// streamFrame is a JSON message sent to viewers.
+// most frames are deltas, keyframes are sent to new viewers and ones that dropped a frame.
+type streamFrame struct {
+	Tick     int  `json:"tick"`
+	Keyframe bool `json:"keyframe"` // food has every source, not just changes
+
+	// per ant: x and y as little endian uint16, then state | caste << 1
+	Ants []byte `json:"ants"`
+
+	// food changed since the last frame as x, y, amount. removed food has amount -1.
+	Food [][3]float32 `json:"food"`
+
+	// per type, STREAM_GRID * STREAM_GRID row major cells of summed pheromone, see STREAM_PHEROMONE_SCALE
+	Pheromones map[string][]byte `json:"pheromones"`
+
+	Predators [][2]float32       `json:"predators"`
+	Stats     map[string]float64 `json:"stats"`
+
+	// sent with keyframes, and every STREAM_WORLD_EVERY ticks
+	World  *worldSnapshot    `json:"world,omitempty"`
+	Colors map[string]string `json:"colors,omitempty"` // pheromone colors by type
+}
+
+func NewStreamer(every int) *Streamer {
+	return &Streamer{every: max(1, every), clients: map[*streamClient]bool{}}
+}
+
+// stream publishes a frame if the game is streaming and a frame is due.
+func (g *Game) stream() {
+	if g.streamer != nil && g.tickCount%g.streamer.every == 0 {
+		g.streamer.publish(g)
+	}
+}
+
+// streamKeyframes publishes a frame for viewers still waiting on a keyframe, for frames where no tick ran.
+// otherwise viewers joining a paused game would wait until it's resumed.
+func (g *Game) streamKeyframes() {
+	if g.streamer != nil && g.streamer.waiting() {
+		g.streamer.publish(g)
+	}
+}
+
+func (s *Streamer) waiting() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		if c.keyframe {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Streamer) publish(g *Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.clients) == 0 {
+		// everyone who joins gets a keyframe, no need to track deltas
+		s.food = nil
+		return
+	}
+
+	frame := streamFrame{
+		Tick:       g.tickCount,
+		Ants:       encodeAnts(g.ants),
+		Food:       [][3]float32{},
+		Pheromones: g.pheromoneSummary(),
+		Predators:  make([][2]float32, 0, len(g.predators)),
+		Stats:      map[string]float64{},
+	}
+
+	for _, p := range g.predators {
+		frame.Predators = append(frame.Predators, [2]float32{float32(p.X), float32(p.Y)})
+	}
+
+	for _, m := range g.Stats().metrics() {
+		frame.Stats[m.name] = m.value
+	}
+
+	// diff food against the last frame
+	all := make([][3]float32, 0, g.food.Len())
+	food := make(map[*Food]int, g.food.Len())
+	for f := range g.food.PointsIter() {
+		v := [3]float32{float32(f.X), float32(f.Y), float32(f.amount)}
+		all = append(all, v)
+		food[f] = f.amount
+		if amount, ok := s.food[f]; !ok || amount != f.amount {
+			frame.Food = append(frame.Food, v)
+		}
+	}
+	for f := range s.food {
+		if _, ok := food[f]; !ok {
+			frame.Food = append(frame.Food, [3]float32{float32(f.X), float32(f.Y), -1})
+		}
+	}
+	s.food = food
+
+	world := g.worldSnapshot()
+	if g.tickCount-s.lastWorld >= STREAM_WORLD_EVERY {
+		frame.World = &world
+		s.lastWorld = g.tickCount
+	}
+
+	delta, err := json.Marshal(frame)
+	if err != nil {
+		log.Printf("error encoding stream frame: %v", err)
+		return
+	}
+
+	var key []byte
+	for c := range s.clients {
+		msg := delta
+		if c.keyframe {
+			if key == nil {
+				frame.Keyframe = true
+				frame.Food = all
+				frame.World = &world
+				frame.Colors = pheromoneColors(g.pheromoneTypes)
+				if key, err = json.Marshal(frame); err != nil {
+					log.Printf("error encoding stream keyframe: %v", err)
+					return
+				}
+			}
+			msg = key
+		}
+
+		select {
+		case c.frames <- msg:
+			c.keyframe = false
+		default:
+			// slow viewer, it missed a delta so it needs to start over
+			c.keyframe = true
+		}
+	}
+}
+
+func encodeAnts(ants []*Ant) []byte {
+	buf := make([]byte, 0, len(ants)*5)
+	for _, ant := range ants {
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(math.Round(max(0, min(math.MaxUint16, ant.X)))))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(math.Round(max(0, min(math.MaxUint16, ant.Y)))))
+		buf = append(buf, byte(ant.state)|byte(ant.caste)<<1)
+	}
+
+	return buf
+}
+
+// pheromoneSummary sums each pheromone field over a coarse grid.
+func (g *Game) pheromoneSummary() map[string][]byte {
+	const cell = GAME_SIZE / STREAM_GRID
+
+	summary := make(map[string][]byte, len(g.pheromones))
+	sums := make([]float64, STREAM_GRID*STREAM_GRID)
+	for kind, field := range g.pheromones {
+		clear(sums)
+		for pher := range field.PointsIter() {
+			x := min(max(int(pher.X/cell), 0), STREAM_GRID-1)
+			y := min(max(int(pher.Y/cell), 0), STREAM_GRID-1)
+			sums[y*STREAM_GRID+x] += float64(pher.amount)
+		}
+
+		cells := make([]byte, len(sums))
+		for i, sum := range sums {
+			cells[i] = byte(min(255, math.Round(sum*STREAM_PHEROMONE_SCALE)))
+		}
+		summary[g.pheromoneTypes[kind].Name] = cells
+	}
+
+	return summary
+}
+
+func pheromoneColors(kinds []PheromoneKind) map[string]string {
+	colors := make(map[string]string, len(kinds))
+	for _, kind := range kinds {
+		colors[kind.Name] = fmt.Sprintf("#%02x%02x%02x", kind.Color.R, kind.Color.G, kind.Color.B)
+	}
+
+	return colors
+}
+
+func (s *Streamer) add(c *streamClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c] = true
+}
+
+func (s *Streamer) remove(c *streamClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, c)
+}
+
+func (s *Streamer) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		log.Printf("error accepting viewer: %v", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	c := &streamClient{frames: make(chan []byte, STREAM_CLIENT_BUFFER), keyframe: true}
+	s.add(c)
+	defer s.remove(c)
+
+	// viewers don't send anything, but reading handles pings and closes
+	ctx := conn.CloseRead(r.Context())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-c.frames:
+			writeCtx, cancel := context.WithTimeout(ctx, STREAM_WRITE_TIMEOUT)
+			err := conn.Write(writeCtx, websocket.MessageText, msg)
+			cancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Streamer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(viewerHTML)
+	})
+	mux.HandleFunc("GET /ws", s.serveWS)
+	return mux
+}
+
+// serveStream serves the viewer at / and the websocket at /ws on addr in the background.
+func serveStream(addr string, s *Streamer) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening for stream: %w", err)
+	}
+
+	go func() {
+		if err := http.Serve(lis, s.handler()); err != nil {
+			log.Printf("stream server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("streaming to viewers on http://%s", lis.Addr())
+	return nil
+}
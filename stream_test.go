@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	game := newTestGame(Scenario{
+		Food: []FoodPatch{{X: 100, Y: 100, Rows: 1, Cols: 2}},
+	}, func(p *Params) {
+		p.AntCount = 3
+	})
+	game.streamer = NewStreamer(1)
+
+	server := httptest.NewServer(game.streamer.handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	// wait for the viewer to be registered
+	require.Eventually(t, func() bool {
+		game.streamer.mu.Lock()
+		defer game.streamer.mu.Unlock()
+		return len(game.streamer.clients) == 1
+	}, time.Second, time.Millisecond)
+
+	read := func() streamFrame {
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+
+		var frame streamFrame
+		require.NoError(t, json.Unmarshal(data, &frame))
+		return frame
+	}
+
+	require.NoError(t, game.Update())
+	key := read()
+	require.True(t, key.Keyframe)
+	require.Len(t, key.Ants, 3*5)
+	require.Len(t, key.Food, 2)
+	require.NotNil(t, key.World)
+	require.Len(t, key.World.Hills, 1)
+	require.Len(t, key.Pheromones["foraging"], STREAM_GRID*STREAM_GRID)
+
+	// only changed food is sent after the keyframe, removed food has amount -1
+	for food := range game.food.PointsIter() {
+		food.amount = 0
+		break
+	}
+	require.NoError(t, game.Update())
+	delta := read()
+	require.False(t, delta.Keyframe)
+	require.Len(t, delta.Food, 1)
+	require.Equal(t, float32(-1), delta.Food[0][2])
+	require.Nil(t, delta.World)
+}
+
+func TestStreamPaused(t *testing.T) {
+	game := newTestGame(Scenario{}, func(p *Params) {
+		p.AntCount = 3
+	})
+	game.streamer = NewStreamer(1)
+	game.setPaused(true)
+
+	server := httptest.NewServer(game.streamer.handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	require.Eventually(t, game.streamer.waiting, time.Second, time.Millisecond)
+
+	// viewers joining a paused game get a keyframe without a tick
+	require.NoError(t, game.Update())
+	require.Zero(t, game.tickCount)
+	require.False(t, game.streamer.waiting())
+
+	_, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+
+	var frame streamFrame
+	require.NoError(t, json.Unmarshal(data, &frame))
+	require.True(t, frame.Keyframe)
+	require.Len(t, frame.Ants, 3*5)
+}
+
+func TestViewerPage(t *testing.T) {
+	server := httptest.NewServer(NewStreamer(1).handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+}
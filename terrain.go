@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+
+	"github.com/rafibayer/ants-again/vector"
+)
+
+const (
+	TERRAIN_CELL_SIZE   = GAME_SIZE / 100.0
+	TERRAIN_MIN_SPEED   = 0.05 // terrain never stops ants completely
+	TERRAIN_IMAGE_SCALE = 2.0  // terrain image brightness is multiplied by this, so mid gray is normal ground
+)
+
+// Terrain scales ant speed per cell, mud and sand slow ants down while roads speed them up.
+// 1.0 is normal ground.
+type Terrain struct {
+	size       float64
+	cols, rows int
+	speed      []float64
+}
+
+// TerrainSpec describes the terrain of a scenario.
+// the image, if any, is applied first and regions are painted on top in order.
+type TerrainSpec struct {
+	Image   string          `json:"image"` // path relative to the scenario, stretched over the world. brightness is speed, see TERRAIN_IMAGE_SCALE
+	Regions []TerrainRegion `json:"regions"`
+
+	img image.Image // decoded by LoadScenario
+}
+
+// TerrainRegion is a rectangle of terrain with its top left corner at X, Y.
+type TerrainRegion struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Speed  float64 `json:"speed"` // multiplies AntSpeed
+}
+
+func NewTerrain(size, width, height float64) *Terrain {
+	cols := max(1, int(width/size))
+	rows := max(1, int(height/size))
+
+	speed := make([]float64, cols*rows)
+	for i := range speed {
+		speed[i] = 1.0
+	}
+
+	return &Terrain{size: size, cols: cols, rows: rows, speed: speed}
+}
+
+func (t *Terrain) index(x, y int) int {
+	return min(max(y, 0), t.rows-1)*t.cols + min(max(x, 0), t.cols-1)
+}
+
+// Speed returns the speed multiplier at v, points out of bounds use the nearest edge cell.
+func (t *Terrain) Speed(v vector.Vector) float64 {
+	return t.speed[t.index(int(v.X/t.size), int(v.Y/t.size))]
+}
+
+// fill sets the speed of every cell with its center inside the rectangle.
+func (t *Terrain) fill(r TerrainRegion) {
+	for y := range t.rows {
+		for x := range t.cols {
+			cx := (float64(x) + 0.5) * t.size
+			cy := (float64(y) + 0.5) * t.size
+			if cx >= r.X && cx < r.X+r.Width && cy >= r.Y && cy < r.Y+r.Height {
+				t.speed[t.index(x, y)] = max(TERRAIN_MIN_SPEED, r.Speed)
+			}
+		}
+	}
+}
+
+// fillImage stretches img over the terrain, sampling it at each cells center.
+func (t *Terrain) fillImage(img image.Image) {
+	bounds := img.Bounds()
+	for y := range t.rows {
+		for x := range t.cols {
+			px := bounds.Min.X + int((float64(x)+0.5)/float64(t.cols)*float64(bounds.Dx()))
+			py := bounds.Min.Y + int((float64(y)+0.5)/float64(t.rows)*float64(bounds.Dy()))
+
+			gray := color.GrayModel.Convert(img.At(px, py)).(color.Gray)
+			t.speed[t.index(x, y)] = max(TERRAIN_MIN_SPEED, float64(gray.Y)/255*TERRAIN_IMAGE_SCALE)
+		}
+	}
+}
+
+// decodeTerrainImage decodes a terrain image referenced by a scenario, path is used in errors.
+func decodeTerrainImage(path string, data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding terrain image %s: %w", path, err)
+	}
+
+	return img, nil
+}
+
+func (g *Game) loadTerrain(spec *TerrainSpec) {
+	if spec.img != nil {
+		g.terrain.fillImage(spec.img)
+	}
+
+	for _, r := range spec.Regions {
+		g.terrain.fill(r)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rafibayer/ants-again/util"
+	"github.com/rafibayer/ants-again/vector"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerrainRegions(t *testing.T) {
+	game := newTestGame(Scenario{
+		Terrain: &TerrainSpec{Regions: []TerrainRegion{
+			{X: 0, Y: 0, Width: 100, Height: 100, Speed: 0.5},
+			{X: 50, Y: 0, Width: 50, Height: 50, Speed: 2},
+		}},
+	}, nil)
+
+	require.Equal(t, 0.5, game.terrain.Speed(vector.Vector{X: 10, Y: 90}))
+	require.Equal(t, 2.0, game.terrain.Speed(vector.Vector{X: 60, Y: 10}))
+	require.Equal(t, 1.0, game.terrain.Speed(vector.Vector{X: 500, Y: 500}))
+
+	// out of bounds uses the nearest edge
+	require.Equal(t, 0.5, game.terrain.Speed(vector.Vector{X: -10, Y: 90}))
+}
+
+func TestTerrainFasterRoute(t *testing.T) {
+	// food straight ahead of the hill, fast ground on the left and slow ground on the right
+	traffic := func(seed uint64) (fast, slow int) {
+		util.Seed(seed)
+		game := newTestGame(Scenario{
+			Food: []FoodPatch{{X: 500, Y: 200, Rows: 5, Cols: 5}},
+			Terrain: &TerrainSpec{Regions: []TerrainRegion{
+				{X: 0, Y: 0, Width: 500, Height: GAME_SIZE, Speed: 1.5},
+				{X: 500, Y: 0, Width: 500, Height: GAME_SIZE, Speed: 0.5},
+			}},
+		}, func(p *Params) {
+			p.AntCount = 200
+		})
+
+		// count ants crossing the line between the hill and the food
+		const line = 350.0
+		prev := map[*Ant]float64{}
+		for range 20 * TPS {
+			require.NoError(t, game.Update())
+			for _, ant := range game.ants {
+				if y, ok := prev[ant]; ok && (y-line)*(ant.Y-line) < 0 {
+					if ant.X < 500 {
+						fast++
+					} else {
+						slow++
+					}
+				}
+				prev[ant] = ant.Y
+			}
+		}
+
+		return fast, slow
+	}
+
+	for seed := range uint64(3) {
+		fast, slow := traffic(seed)
+		require.Greater(t, fast, slow, "seed %d", seed)
+	}
+}
+
+func TestTerrainImage(t *testing.T) {
+	dir := t.TempDir()
+
+	// left half black, right half white
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(0, 0, color.Gray{Y: 0})
+	img.SetGray(1, 0, color.Gray{Y: 255})
+
+	f, err := os.Create(filepath.Join(dir, "terrain.png"))
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, img))
+	require.NoError(t, f.Close())
+
+	scenario := `{"hills": [{"x": 500, "y": 500}], "terrain": {"image": "terrain.png"}}`
+	path := filepath.Join(dir, "scenario.json")
+	require.NoError(t, os.WriteFile(path, []byte(scenario), 0o644))
+
+	loaded, err := LoadScenario(path)
+	require.NoError(t, err)
+
+	params := DefaultParams
+	params.AntCount = 0
+	game := NewGame(&params, loaded)
+
+	require.Equal(t, TERRAIN_MIN_SPEED, game.terrain.Speed(vector.Vector{X: 100, Y: 500}))
+	require.Equal(t, TERRAIN_IMAGE_SCALE, game.terrain.Speed(vector.Vector{X: 900, Y: 500}))
+}
+
+func TestTerrainMissingImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	scenario := `{"hills": [{"x": 500, "y": 500}], "terrain": {"image": "missing.png"}}`
+	require.NoError(t, os.WriteFile(path, []byte(scenario), 0o644))
+
+	_, err := LoadScenario(path)
+	require.Error(t, err)
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"cmp"
 	"image"
+	"log"
+	"slices"
 
 	"github.com/ebitengine/debugui"
 )
@@ -17,6 +20,30 @@ func ui(g *Game) func(ctx *debugui.Context) error {
 
 		// Window(title, default position/size, contents)
 		ctx.Window("settings", image.Rect(x0, y0, x1, y1), func(layout debugui.ContainerLayout) {
+			ctx.Header("simulation", true, func() {
+				ctx.Text(g.playbackStatus())
+
+				pause := "pause (space)"
+				if g.paused {
+					pause = "resume (space)"
+				}
+				ctx.Button(pause).On(func() {
+					g.setPaused(!g.paused)
+				})
+				ctx.Button("step (.)").On(func() {
+					g.step(1)
+				})
+
+				ctx.Text("speed ([ ])")
+				ctx.SliderF(&g.speed, SPEED_MIN, SPEED_MAX, 0.25, 2)
+
+				ctx.Text("fast-forward ticks")
+				ctx.Slider(&g.fastForwardLength, TPS, 600*TPS, TPS)
+				ctx.Button("fast-forward (f)").On(func() {
+					g.fastForward(g.fastForwardLength)
+				})
+			})
+
 			// Slider for ant speed
 			ctx.Text("ant speed")
 			// SliderF takes a pointer to float64, low, high, step, and number of decimals
@@ -31,14 +58,99 @@ func ui(g *Game) func(ctx *debugui.Context) error {
 			ctx.Text("pheromone sense radius")
 			ctx.SliderF(&g.params.PheromoneSenseRadius, 50.0, 250, 5, 1)
 
+			ctx.Text("ant energy (0: no starvation)")
+			ctx.SliderF(&g.params.AntEnergy, 0, GAME_SIZE*10, GAME_SIZE/10, 0)
+
+			ctx.Text("ant lifespan ticks (0: immortal)")
+			ctx.Slider(&g.params.AntLifespan, 0, 600*TPS, TPS)
+
+			ctx.Text("food per new ant (0: no reproduction)")
+			ctx.Slider(&g.params.ColonySpawnCost, 0, 50, 1)
+
+			ctx.Text("repellent pheromone (0: no repellent)")
+			ctx.Slider(&g.params.AntRepellentStart, 0, 50, 1)
+
+			ctx.Text("alarm pheromone (0: no alarm)")
+			ctx.Slider(&g.params.AntAlarmStart, 0, 50, 1)
+
+			ctx.Text("predator speed")
+			ctx.SliderF(&g.params.PredatorSpeed, 0, 5, 0.1, 1)
+
+			ctx.Checkbox(&g.params.PathIntegration, "path integration")
+			ctx.Text("path integration noise / weight")
+			ctx.SliderF(&g.params.PathIntegrationNoise, 0, 45, 1, 0)
+			ctx.SliderF(&g.params.PathIntegrationWeight, 0, 2, 0.05, 2)
+
+			ctx.Header("castes", false, func() {
+				for i := range g.params.Castes {
+					ctx.IDScope(g.params.Castes[i].Name, func() {
+						ctx.Text(g.params.Castes[i].Name + " ratio")
+						ctx.SliderF(&g.params.Castes[i].Ratio, 0, 1, 0.05, 2)
+					})
+				}
+			})
+
+			ctx.Text("behavior")
+			names := behaviorNames()
+			behavior := max(0, slices.Index(names, cmp.Or(g.params.Behavior, DEFAULT_BEHAVIOR)))
+			ctx.Dropdown(&behavior, names).On(func() {
+				g.params.Behavior = names[behavior]
+			})
+
+			ctx.Text("pheromone deposit")
+			ctx.Dropdown(&g.params.DepositModeIndex, depositModes)
+
+			ctx.Text("pheromone occlusion")
+			ctx.Dropdown(&g.params.PheromoneOcclusionModeIndex, occlusionModes)
+
 			ctx.Checkbox(&g.params.DebugDrawSensorRange, "debug sense range")
 
+			if g.recorder != nil {
+				ctx.Button("export stats").On(func() {
+					if err := g.recorder.Save(g.recordPath); err != nil {
+						log.Printf("error exporting stats: %v", err)
+					}
+				})
+			}
+
 			ctx.Text("Cursor mode (left: add, right: remove)")
 			ctx.Dropdown(&g.cursorModeIndex, cursorOptions)
+			ctx.Header("brush", false, func() {
+				ctx.Text("shape (food, obstacles)")
+				ctx.Dropdown(&g.brushShapeIndex, brushShapes)
+				ctx.Text("radius")
+				ctx.SliderF(&g.brushRadius, 0, 100, 1, 0)
+				ctx.Text("food amount")
+				ctx.Slider(&g.brushFoodAmount, 1, 500, 1)
+				ctx.Text("ants per click")
+				ctx.Slider(&g.brushAntCount, 1, 500, 1)
+			})
+			ctx.Button("undo (ctrl-z)").On(func() {
+				g.history.undo()
+			})
+			ctx.Button("redo (ctrl-y)").On(func() {
+				g.history.redo()
+			})
 
 			ctx.Text("Boundary mode")
 			ctx.Dropdown(&g.params.BoundaryModeIndex, boundaryModes)
+
+			ctx.Text("Wall mode")
+			ctx.Dropdown(&g.params.WallModeIndex, wallModes)
 		})
+
+		if ant := g.selected; ant != nil {
+			ctx.Window("ant", image.Rect(x1+10, y0, x1+10+width, y0+250), func(layout debugui.ContainerLayout) {
+				for _, line := range g.inspect(ant) {
+					ctx.Text(line)
+				}
+
+				ctx.Checkbox(&g.followSelected, "follow")
+				ctx.Button("deselect").On(func() {
+					g.selected = nil
+				})
+			})
+		}
 		return nil
 	}
 }
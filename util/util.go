@@ -1,6 +1,45 @@
 package util
 
-import "math/rand/v2"
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// seeded replaces the global random source once Seed is called.
+var seeded atomic.Pointer[lockedRand]
+
+type lockedRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// Seed makes all following random numbers reproducible, for every caller in the process.
+func Seed(seed uint64) {
+	seeded.Store(&lockedRand{rng: rand.New(rand.NewPCG(seed, seed))})
+}
+
+func randFloat() float64 {
+	r := seeded.Load()
+	if r == nil {
+		return rand.Float64()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+func randIntN(n int) int {
+	r := seeded.Load()
+	if r == nil {
+		return rand.IntN(n)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.IntN(n)
+}
 
 // remap [-1, 1] to [0, 1]
 func LinearRemap(x float64) float64 {
@@ -8,16 +47,16 @@ func LinearRemap(x float64) float64 {
 }
 
 func Chance(odds float64) bool {
-	return rand.Float64() < odds
+	return randFloat() < odds
 }
 
 func Rand(min, max float64) float64 {
-	return min + rand.Float64()*(max-min)
+	return min + randFloat()*(max-min)
 }
 
 // [min, max)
 func RandInt(min, max int) int {
-	return rand.IntN(max-min) + min
+	return randIntN(max-min) + min
 }
 
 func Clamp(low, x, high float64) float64 {
@@ -0,0 +1,72 @@
+package vector
+
+import "math"
+
+// SegmentIntersection returns the fraction t in [0, 1] along p0->p1 at which it crosses q0->q1.
+// parallel segments never intersect.
+func SegmentIntersection(p0, p1, q0, q1 Vector) (float64, bool) {
+	r := p1.Sub(p0)
+	s := q1.Sub(q0)
+
+	denom := r.Cross(s)
+	if denom == 0 {
+		return 0, false
+	}
+
+	qp := q0.Sub(p0)
+	t := qp.Cross(s) / denom
+	u := qp.Cross(r) / denom
+
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, false
+	}
+
+	return t, true
+}
+
+// DistanceToSegment returns the distance from p to the closest point on a->b.
+func DistanceToSegment(p, a, b Vector) float64 {
+	ab := b.Sub(a)
+	len2 := ab.Dot(ab)
+	if len2 == 0 {
+		return p.Distance(a)
+	}
+
+	t := p.Sub(a).Dot(ab) / len2
+	t = max(0, min(1, t))
+
+	return p.Distance(a.Add(ab.Mul(t)))
+}
+
+// SegmentIntersectsRect reports whether p0->p1 passes through the axis aligned rectangle [min, max].
+func SegmentIntersectsRect(p0, p1, min, max Vector) bool {
+	// slab method: clip the segment's parameter range against each axis
+	tMin, tMax := 0.0, 1.0
+	d := p1.Sub(p0)
+
+	for _, axis := range [2]struct{ p, d, lo, hi float64 }{
+		{p0.X, d.X, min.X, max.X},
+		{p0.Y, d.Y, min.Y, max.Y},
+	} {
+		if axis.d == 0 {
+			if axis.p < axis.lo || axis.p > axis.hi {
+				return false
+			}
+			continue
+		}
+
+		t0 := (axis.lo - axis.p) / axis.d
+		t1 := (axis.hi - axis.p) / axis.d
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+
+		tMin = math.Max(tMin, t0)
+		tMax = math.Min(tMax, t1)
+		if tMin > tMax {
+			return false
+		}
+	}
+
+	return true
+}
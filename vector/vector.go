@@ -11,7 +11,8 @@ var (
 )
 
 type Vector struct {
-	X, Y float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
 func (p Vector) Distance2(other Vector) float64 {
@@ -69,3 +70,17 @@ func (v Vector) Rotate(deg float64) Vector {
 		Y: v.X*sin + v.Y*cos,
 	}
 }
+
+func (p Vector) Dot(other Vector) float64 {
+	return p.X*other.X + p.Y*other.Y
+}
+
+// z component of the cross product of p and other extended to 3d.
+func (p Vector) Cross(other Vector) float64 {
+	return p.X*other.Y - p.Y*other.X
+}
+
+// rotates p 90 degrees.
+func (p Vector) Perpendicular() Vector {
+	return Vector{X: -p.Y, Y: p.X}
+}